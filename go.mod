@@ -1,9 +1,11 @@
 module github.com/jmanero/glug
 
-go 1.21.4
+go 1.22
 
 require (
 	github.com/itchyny/timefmt-go v0.1.5
+	github.com/klauspost/compress v1.18.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cobra v1.8.0
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/multierr v1.11.0
@@ -2,10 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
+	// Embed the IANA time zone database for hosts without one
+	_ "time/tzdata"
+
 	"github.com/jmanero/glug/pkg/logger"
 	"github.com/spf13/cobra"
 	"storj.io/common/memory"
@@ -27,6 +34,13 @@ var Options = logger.RotatorOptions{
 	MaxSize:    32 * memory.MiB,
 	MinSize:    512 * memory.KiB,
 	CreateMode: 0644,
+	Naming:     logger.NamingTimestamp,
+	Lock:       logger.LockFail,
+
+	OnError:         logger.ErrorExit,
+	ErrorBufferSize: 4 * memory.MiB,
+
+	MaxLineLength: 64 * memory.KiB,
 }
 
 func init() {
@@ -35,20 +49,77 @@ func init() {
 	Flags.Var(&Options.MaxSize, "max-size", "Maximum byte-size of the output log-file")
 	Flags.DurationVar(&Options.MaxAge, "max-age", time.Hour*24*7, "Maximum age for the output log-file")
 	Flags.Var(&Options.MinSize, "min-size", "Block rotation of small log-files by age until they reach a minimum size threshold")
-	Flags.IntVar(&Options.Count, "count", 4, "Number of rotated log-files to retain")
+	Flags.IntVar(&Options.Count, "count", 4, "Number of rotated log-files to retain. -1 disables retention by count")
+	Flags.IntVar(&Options.MinCount, "min-count", 0, "Number of rotated log-files to retain when removing them to maintain min-free")
+	Flags.Var(&Options.RetainFor, "retain-for", "Remove rotated log-files older than a duration, e.g. 30d. Age is parsed from the file name suffix, or its mtime")
+	Flags.Var(&Options.MinFree, "min-free", "Minimum free space on the log-file's filesystem. Oldest rotated log-files are removed, then the output log-file is truncated, to maintain it")
+	Flags.Var(&Options.MaxTotalSize, "max-total-size", "Maximum byte-size of the output log-file and all rotated log-files. Oldest rotated log-files are removed first")
+	Flags.Var(&Options.RotateAt, "rotate-at", "Rotate the output log-file on calendar boundaries instead of max-age: hourly, daily[@HH:MM], weekly[@DAY], monthly, or a cron expression")
+	Flags.DurationVar(&Options.CheckInterval, "check-interval", time.Minute, "Interval to check the output log-file's age for rotation without incoming writes. Zero disables checks between writes")
 	Flags.StringVar(&Options.Pattern, "pattern", "%Y-%m-%dT%H%M%S", "strftime format string for rotated file name suffixes")
+	Flags.Var(&Options.Naming, "naming", "Naming scheme for rotated log-files: timestamp suffixes formatted by --pattern, or numeric suffixes shifted on each rotation")
+	Flags.StringVar(&Options.ArchiveDir, "archive-dir", "", "Existing directory for rotated log-files, which may be on another filesystem (default LOGFILE's directory)")
+	Flags.Var(&Options.Timezone, "timezone", "IANA time zone name, or Local, for rotated file name suffixes and rotate-at schedules")
 	Flags.Var(&Options.CreateMode, "mode", "Mode bits for log-file creation. Octal values are supported with a leading `0`")
+	Flags.StringVar(&Options.Compress, "compress", "", "Compress rotated log-files in the background. Supported values: gzip, zstd")
+	Flags.IntVar(&Options.CompressLevel, "compress-level", 0, "Compression level for rotated log-files. Zero selects the compressor's default")
+	Flags.IntVar(&Options.CompressDelay, "compress-delay", 0, "Number of the newest rotated log-files to leave uncompressed")
+	Flags.StringVar(&Options.Processor, "processor", "", "Shell command run on each rotated log-file with the file as its stdin. If it exits successfully, the file is replaced with its stdout")
+	Flags.DurationVar(&Options.ProcessorTimeout, "processor-timeout", 5*time.Minute, "Maximum run time of the processor. The rotated log-file is kept unchanged if it is exceeded. Zero disables the limit")
+	Flags.BoolVar(&Options.Control, "control", true, "Serve a control socket for the running logger")
+	Flags.StringVar(&Options.ControlSocket, "control-socket", "", "Path of the control socket (default LOGFILE.sock)")
+	Flags.Var(&Options.OnError, "on-error", "Behavior when writing to the log-file fails: exit, drop input, buffer input in memory, or retry with backoff. Policies other than exit report failed rotation checks between writes on stderr and retry them with backoff")
+	Flags.Var(&Options.ErrorBufferSize, "error-buffer-size", "Maximum byte-size of input buffered in memory by --on-error buffer")
+	Flags.Var(&Options.MaxLineLength, "max-line-length", "Maximum byte-size of a line. Longer lines are split. Zero disables the limit")
+	Flags.Var(&Options.Timestamp, "timestamp", "Prefix each line with its arrival time: tai64n, rfc3339, rfc3339nano, or a strftime format string")
+	Flags.Var(&Options.Select, "select", "svlogd line selection rule: +PATTERN or -PATTERN selects or deselects matching lines for the log-file, and ePATTERN or EPATTERN for alerts on stderr. May be repeated")
+	Flags.StringVar(&SelectFile, "select-file", "", "File of svlogd line selection rules, one per line, applied before --select rules")
+	Flags.StringVar(&Options.Remote, "udp", "", "Send selected lines to a UDP address, e.g. 10.0.0.1:514")
+	Flags.BoolVar(&Options.RemoteOnly, "udp-only", false, "Send selected lines to the --udp address instead of the log-file")
+	Flags.StringVar(&Options.Prefix, "prefix", "", "Prefix for lines written to the log-file, alerts and the --udp address")
+	Flags.StringVar(&ConfigFile, "config", "", "svlogd config file. Defaults to config in LOGFILE's directory, if it exists. Flags that are set explicitly override its settings")
+	Flags.Var(&Options.Lock, "lock", "Behavior when another process holds LOGFILE.lock: fail, wait for release, or delegate input to the holder's control socket")
 
-	CLI.AddCommand(&cobra.Command{
-		Use:   "rotate LOGFILE",
-		Short: "Perform rotation upon the specified log file",
-		Args:  cobra.ExactArgs(1),
-		RunE:  Rotate,
-	})
+	RotateCmd.Flags().BoolVar(&Force, "force", false, "Rotate the log file regardless of its size and age")
+
+	CLI.AddCommand(&RotateCmd, &VersionsCmd, &ControlCmd)
+}
+
+// RotateCmd applies rotation to a log file
+var RotateCmd = cobra.Command{
+	Use:   "rotate LOGFILE",
+	Short: "Perform rotation upon the specified log file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  Rotate,
+}
+
+// VersionsCmd lists the rotated versions of a log file
+var VersionsCmd = cobra.Command{
+	Use:   "versions LOGFILE",
+	Short: "List the rotated versions of the specified log file, oldest first",
+	Args:  cobra.ExactArgs(1),
+	RunE:  Versions,
+}
+
+// ControlCmd sends commands to a running logger
+var ControlCmd = cobra.Command{
+	Use:   "control LOGFILE COMMAND",
+	Short: "Send a command (rotate, rotate force, reopen, flush, status, versions) to the running logger for a log file",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  Control,
 }
 
+// Force rotation from the rotate subcommand
+var Force bool
+
+// SelectFile contains line selection rules for the logger
+var SelectFile string
+
+// ConfigFile is an svlogd config file for the logger
+var ConfigFile string
+
 func main() {
-	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
 	err := CLI.ExecuteContext(ctx)
 	if err != nil {
@@ -57,13 +128,168 @@ func main() {
 	}
 }
 
-// Logger runs the log writer, reading from STDIN
+// Logger runs the log writer, reading from STDIN. SIGHUP reloads the configuration files
 func Logger(cmd *cobra.Command, args []string) error {
-	return logger.Run(cmd.Context(), cmd.InOrStdin(), args[0], Options)
+	opts, err := Configure(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	reload := logger.TriggerReload(func() (logger.RotatorOptions, error) {
+		return Configure(cmd, args[0])
+	})
+
+	return logger.Run(cmd.Context(), cmd.InOrStdin(), args[0], opts, Triggers(cmd.Context(), reload))
+}
+
+// Triggers maps SIGALRM to forced rotation and SIGHUP to the reload trigger, following svlogd
+func Triggers(ctx context.Context, reload logger.Trigger) <-chan logger.Trigger {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGALRM, syscall.SIGHUP)
+
+	triggers := make(chan logger.Trigger)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			var trigger logger.Trigger
+
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-signals:
+				if sig == syscall.SIGALRM {
+					trigger = logger.TriggerRotate
+				} else {
+					trigger = reload
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case triggers <- trigger:
+			}
+		}
+	}()
+
+	return triggers
 }
 
-// Rotate applies rotation logic once to the current output file and rotated versions
+// Configure returns Options with settings from the svlogd config file and line selection rules from the select
+// file applied. Settings from flags that were set explicitly are not overridden
+func Configure(cmd *cobra.Command, path string) (opts logger.RotatorOptions, err error) {
+	opts = Options
+	opts.Alerts = cmd.ErrOrStderr()
+
+	config, err := ReadConfig(cmd, path)
+	if err != nil {
+		return
+	}
+
+	var selectors logger.Selectors
+
+	if SelectFile != "" {
+		selectors, err = logger.ReadSelectors(SelectFile)
+		if err != nil {
+			return
+		}
+	}
+
+	// Rules from flags are applied after rules from files
+	opts.Select = slices.Concat(selectors, config.Select, Options.Select)
+
+	flags := cmd.Flags()
+
+	if config.MaxSize != nil && !flags.Changed("max-size") {
+		opts.MaxSize = *config.MaxSize
+	}
+
+	if config.Count != nil && !flags.Changed("count") {
+		opts.Count = *config.Count
+	}
+
+	if config.MinCount != nil && !flags.Changed("min-count") {
+		opts.MinCount = *config.MinCount
+	}
+
+	if config.MaxAge != nil && !flags.Changed("max-age") {
+		opts.MaxAge = *config.MaxAge
+	}
+
+	if config.Remote != nil && !flags.Changed("udp") {
+		opts.Remote = *config.Remote
+
+		if !flags.Changed("udp-only") {
+			opts.RemoteOnly = config.RemoteOnly
+		}
+	}
+
+	if config.Prefix != nil && !flags.Changed("prefix") {
+		opts.Prefix = *config.Prefix
+	}
+
+	if config.Processor != nil && !flags.Changed("processor") {
+		opts.Processor = *config.Processor
+	}
+
+	return
+}
+
+// ReadConfig reads the svlogd config file from the --config flag, or from LOGFILE's directory if it exists
+func ReadConfig(cmd *cobra.Command, path string) (logger.Config, error) {
+	if cmd.Flags().Changed("config") {
+		if ConfigFile == "" {
+			return logger.Config{}, nil
+		}
+
+		return logger.ReadConfig(ConfigFile)
+	}
+
+	config, err := logger.ReadConfig(logger.ConfigPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return logger.Config{}, nil
+	}
+
+	return config, err
+}
+
+// Rotate applies rotation logic once to the current output file and rotated versions. Rotation is
+// delegated to the running logger for the output file if one exists
 func Rotate(cmd *cobra.Command, args []string) (err error) {
-	_, err = logger.Rotate(cmd.Context(), args[0], Options)
+	opts, err := Configure(cmd, args[0])
+	if err != nil {
+		return
+	}
+
+	_, err = logger.Rotate(cmd.Context(), args[0], opts, Force)
 	return
 }
+
+// Versions prints the rotated versions of the output file, one per line
+func Versions(cmd *cobra.Command, args []string) error {
+	opts, err := Configure(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	versions, err := logger.Versions(cmd.Context(), args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		fmt.Fprintln(cmd.OutOrStdout(), version)
+	}
+
+	return nil
+}
+
+// Control sends a command to the running logger for the output file and prints its response
+func Control(cmd *cobra.Command, args []string) error {
+	response, err := logger.Control(cmd.Context(), Options.ControlPath(args[0]), args[1:]...)
+	fmt.Fprint(cmd.OutOrStdout(), response)
+
+	return err
+}
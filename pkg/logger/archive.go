@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"go.uber.org/multierr"
+)
+
+// VersionPrefix returns the path that rotated versions of an output file are named after: the output file itself,
+// or a file of the same name in ArchiveDir
+func (opts RotatorOptions) VersionPrefix(name string) string {
+	if opts.ArchiveDir == "" {
+		return name
+	}
+
+	return filepath.Join(opts.ArchiveDir, filepath.Base(name))
+}
+
+// versionPrefix returns the path that rotated versions of the output file are named after
+func (rotator *Rotator) versionPrefix() string {
+	return rotator.VersionPrefix(rotator.Name())
+}
+
+// checkArchiveDir ensures that a configured ArchiveDir is an existing directory. It is not created, so that an
+// unmounted archive volume is reported instead of filling the filesystem beneath it
+func (opts RotatorOptions) checkArchiveDir() error {
+	if opts.ArchiveDir == "" {
+		return nil
+	}
+
+	stat, err := os.Stat(opts.ArchiveDir)
+	if err != nil {
+		return err
+	}
+
+	if !stat.IsDir() {
+		return fmt.Errorf("archive directory %s is not a directory", opts.ArchiveDir)
+	}
+
+	return nil
+}
+
+// stagingName returns the hidden path beside the output file that a version is rotated to before archive moves it
+// to ArchiveDir, so that copying to another filesystem does not block input
+func (rotator *Rotator) stagingName(version string) string {
+	return filepath.Join(filepath.Dir(rotator.Name()), "."+filepath.Base(version))
+}
+
+// unstage moves versions that were rotated to their staging names into ArchiveDir, including versions left by
+// failed moves. Failures are reported to Alerts and retried by the next call. The caller must hold the maintenance
+// lock, or have exclusive use of the Rotator
+func (rotator *Rotator) unstage() (err error) {
+	opts := rotator.Options()
+	if opts.ArchiveDir == "" {
+		return
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(rotator.Name()))
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		label, hidden := strings.CutPrefix(entry.Name(), ".")
+		version := filepath.Join(opts.ArchiveDir, label)
+
+		if !hidden || !entry.Type().IsRegular() || !opts.isVersion(rotator.Name(), version) {
+			continue
+		}
+
+		staged := rotator.stagingName(version)
+
+		if merr := moveFile(staged, version); merr != nil {
+			err = multierr.Append(err, merr)
+
+			if opts.Alerts != nil {
+				fmt.Fprintf(opts.Alerts, "unable to move %s to %s: %s\n", staged, version, merr)
+			}
+		}
+	}
+
+	return
+}
+
+// sameFilesystem checks if two paths are on the same filesystem
+func sameFilesystem(a, b string) bool {
+	astat, aerr := os.Stat(a)
+	bstat, berr := os.Stat(b)
+
+	if aerr != nil || berr != nil {
+		return false
+	}
+
+	return astat.Sys().(*syscall.Stat_t).Dev == bstat.Sys().(*syscall.Stat_t).Dev
+}
+
+// moveFile renames src to dst without overwriting an existing dst. If they are on different filesystems, src is
+// copied to dst then removed
+func moveFile(src, dst string) (err error) {
+	if _, err = os.Lstat(dst); err == nil {
+		return &fs.PathError{Op: "move", Path: dst, Err: fs.ErrExist}
+	}
+
+	err = os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return
+	}
+
+	err = copyFile(src, dst)
+	if err != nil {
+		return
+	}
+
+	return os.Remove(src)
+}
+
+// copyFile writes a copy of src to dst
+func copyFile(src, dst string) error {
+	return transformFile(src, dst, func(out, in *os.File) (err error) {
+		_, err = io.Copy(out, in)
+		return
+	})
+}
+
+// transformFile writes the output of transform, reading from src, to a hidden temporary file beside dst so that
+// partial output is never matched as a rotated version. The output is synced to stable storage, given the mode of
+// src, then renamed to dst. The temporary file is removed if any step fails
+func transformFile(src, dst string, transform func(out, in *os.File) error) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+
+	defer in.Close()
+
+	stat, err := in.Stat()
+	if err != nil {
+		return
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(out.Name())
+		}
+	}()
+
+	err = transform(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+
+	err = multierr.Append(err, out.Close())
+	if err != nil {
+		return
+	}
+
+	err = os.Chmod(out.Name(), stat.Mode())
+	if err != nil {
+		return
+	}
+
+	return os.Rename(out.Name(), dst)
+}
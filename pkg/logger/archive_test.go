@@ -0,0 +1,216 @@
+package logger_test
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"storj.io/common/memory"
+)
+
+func TestRotatorArchiveDir(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive")
+
+	_, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{ArchiveDir: archive, Pattern: "%Y-%m-%dT%H%M%S.%f", CreateMode: 0o644})
+	assert.ErrorIs(t, err, os.ErrNotExist, "Archive directory must exist")
+
+	assert.NoError(t, os.Mkdir(archive, 0o755))
+	testArchiveDir(t, dir, archive)
+}
+
+func TestRotatorArchiveDirCrossDevice(t *testing.T) {
+	dir := t.TempDir()
+	testArchiveDir(t, dir, crossDeviceDir(t, dir))
+}
+
+func TestRotatorArchiveDirMoveFailure(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive")
+	alerts := new(strings.Builder)
+
+	assert.NoError(t, os.Mkdir(archive, 0o755))
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%d",
+		ArchiveDir: archive,
+		CreateMode: 0o644,
+		Alerts:     alerts,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	// Moves fail while the archive directory is missing
+	assert.NoError(t, os.Remove(archive))
+
+	for i := 0; i < 2; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+
+		rotated, err := rotator.Force()
+		assert.NoError(t, err, "Rotation does not fail when a version can not be moved")
+		assert.True(t, rotated, "Force returns true after rotation")
+	}
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+	assert.Contains(t, alerts.String(), "unable to move", "Reports move failures to Alerts")
+
+	staged, err := filepath.Glob(filepath.Join(dir, ".log.*"))
+	assert.NoError(t, err)
+	assert.Len(t, staged, 2, "Colliding versions are staged with distinct names")
+
+	// Staged versions are recovered when the archive directory is available again
+	assert.NoError(t, os.Mkdir(archive, 0o755))
+
+	rotator, err = logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Count:      -1,
+		Pattern:    "%Y-%m-%d",
+		ArchiveDir: archive,
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Moves staged versions to the archive directory")
+
+	staged, err = filepath.Glob(filepath.Join(dir, ".log.*"))
+	assert.NoError(t, err)
+	assert.Empty(t, staged, "No staged versions remain")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorArchiveDirReclaim(t *testing.T) {
+	dir := t.TempDir()
+	archive := crossDeviceDir(t, dir)
+
+	opts := logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    12,
+		MaxAge:     time.Hour,
+		Count:      4,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		ArchiveDir: archive,
+		CreateMode: 0o644,
+	}
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), opts)
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 2; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+	}
+
+	_, err = rotator.Write([]byte("Hello"))
+	assert.NoError(t, err, "Write without error")
+
+	// Wait for versions to be moved to the archive directory
+	assert.Eventually(t, func() bool {
+		versions, err := rotator.Versions()
+		return err == nil && len(versions) == 2
+	}, time.Second, 10*time.Millisecond, "Moves versions to the archive directory")
+
+	// Set a threshold that can not be met to exercise every step of reclamation
+	opts.MinFree = memory.Size(math.MaxInt64)
+	assert.NoError(t, rotator.Reconfigure(opts), "Reconfigure without error")
+	assert.NoError(t, rotator.Reclaim(), "Reclaim without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Does not remove versions from an archive directory on another filesystem")
+
+	assert.Zero(t, rotator.Size(), "Truncates output file as a last resort")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+// crossDeviceDir creates a temporary directory on another filesystem than dir, skipping the test if there is none
+func crossDeviceDir(t *testing.T, dir string) string {
+	archive, err := os.MkdirTemp("/dev/shm", "glug-archive-")
+	if err != nil {
+		t.Skip("No second filesystem is available at /dev/shm")
+	}
+
+	t.Cleanup(func() { os.RemoveAll(archive) })
+
+	// Only use the directory if rename fails across the two directories
+	probe := filepath.Join(dir, "probe")
+	assert.NoError(t, os.WriteFile(probe, nil, 0o644))
+	defer os.Remove(probe)
+
+	if err := os.Rename(probe, filepath.Join(archive, "probe")); !errors.Is(err, syscall.EXDEV) {
+		t.Skip("/dev/shm is on the same filesystem as the test directory")
+	}
+
+	return archive
+}
+
+func testArchiveDir(t *testing.T, dir, archive string) {
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%d",
+		ArchiveDir: archive,
+		CreateMode: 0o640,
+		Compress:   "gzip",
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 3; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+
+		rotated, err := rotator.Force()
+		assert.NoError(t, err, "Force without error")
+		assert.True(t, rotated, "Force returns true after rotation")
+	}
+
+	_, err = rotator.Write([]byte("Hello again\n"))
+	assert.NoError(t, err, "Writes to the new output file")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Cleanup removes versions from the archive directory")
+
+	for _, version := range versions {
+		assert.Equal(t, archive, filepath.Dir(version), "Version %s is in the archive directory", version)
+		assert.True(t, logger.IsCompressed(version), "Version %s is compressed", version)
+
+		stat, err := os.Stat(version)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o640), stat.Mode().Perm(), "Version %s keeps the output file's mode", version)
+	}
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	assert.Subset(t, []string{"archive", "log", "log.lock"}, names, "No versions or staged files remain beside the output file")
+
+	data, err := os.ReadFile(filepath.Join(dir, "log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello again\n", string(data))
+}
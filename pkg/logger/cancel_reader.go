@@ -49,8 +49,6 @@ func (reader *CancelReader) Close() error {
 
 // worker reads from the source Reader to the internal buffer
 func (reader *CancelReader) worker(ctx context.Context, src io.Reader) {
-	buffer := make([]byte, 1024)
-
 	// Squash a panic from writing to a closed channel if the reader was preempted during a blocking read
 	defer func() { recover() }()
 
@@ -60,6 +58,9 @@ func (reader *CancelReader) worker(ctx context.Context, src io.Reader) {
 			return
 		}
 
+		// Read into a new buffer: the previous chunk may still be copied by Read after it is received
+		buffer := make([]byte, 1024)
+
 		n, err := src.Read(buffer)
 		if n > 0 {
 			reader.data <- buffer[:n]
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/multierr"
+)
+
+// Compressor implements a compression format for rotated files
+type Compressor interface {
+	// Extension is appended to the names of compressed files
+	Extension() string
+
+	// NewWriter wraps dst with a compressing writer. A zero level selects the format's default
+	NewWriter(dst io.Writer, level int) (io.WriteCloser, error)
+
+	// NewReader wraps src with a decompressing reader
+	NewReader(src io.Reader) (io.ReadCloser, error)
+}
+
+// Compressors are available for the Compress option by name
+var Compressors = map[string]Compressor{
+	"gzip": Gzip{},
+	"zstd": Zstd{},
+}
+
+// checkCompress ensures that Compress names a supported Compressor, and that it accepts CompressLevel
+func (opts RotatorOptions) checkCompress() error {
+	if opts.Compress == "" {
+		return nil
+	}
+
+	compressor, ok := Compressors[opts.Compress]
+	if !ok {
+		return fmt.Errorf("unsupported compression %q", opts.Compress)
+	}
+
+	writer, err := compressor.NewWriter(io.Discard, opts.CompressLevel)
+	if err != nil {
+		return fmt.Errorf("unsupported %s compression level %d: %w", opts.Compress, opts.CompressLevel, err)
+	}
+
+	return writer.Close()
+}
+
+// CompressorFor returns the Compressor matching a file name's extension, or nil if the file is not compressed
+func CompressorFor(name string) Compressor {
+	for _, compressor := range Compressors {
+		if strings.HasSuffix(name, compressor.Extension()) {
+			return compressor
+		}
+	}
+
+	return nil
+}
+
+// IsCompressed checks if a rotated file name has a compression extension
+func IsCompressed(name string) bool {
+	return CompressorFor(name) != nil
+}
+
+// TrimCompression removes a compression extension from a rotated file name
+func TrimCompression(name string) string {
+	if compressor := CompressorFor(name); compressor != nil {
+		return strings.TrimSuffix(name, compressor.Extension())
+	}
+
+	return name
+}
+
+// CompressFile writes a compressed copy of the named file to name + extension then removes the original
+func CompressFile(name string, compressor Compressor, level int) (err error) {
+	err = transformFile(name, name+compressor.Extension(), func(dst, src *os.File) error {
+		return compress(dst, src, compressor, level)
+	})
+
+	if err != nil {
+		return
+	}
+
+	return os.Remove(name)
+}
+
+func compress(dst io.Writer, src io.Reader, compressor Compressor, level int) (err error) {
+	writer, err := compressor.NewWriter(dst, level)
+	if err != nil {
+		return
+	}
+
+	_, err = io.Copy(writer, src)
+	return multierr.Append(err, writer.Close())
+}
+
+// Gzip implements Compressor with compress/gzip
+type Gzip struct{}
+
+// Extension for gzip files
+func (Gzip) Extension() string {
+	return ".gz"
+}
+
+// NewWriter creates a gzip writer. Levels range from 1 (fastest) to 9 (best compression)
+func (Gzip) NewWriter(dst io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	return gzip.NewWriterLevel(dst, level)
+}
+
+// NewReader creates a gzip reader
+func (Gzip) NewReader(src io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(src)
+}
+
+// Zstd implements Compressor with github.com/klauspost/compress/zstd
+type Zstd struct{}
+
+// Extension for zstd files
+func (Zstd) Extension() string {
+	return ".zst"
+}
+
+// NewWriter creates a zstd encoder. Levels range from 1 (fastest) to 22 (best compression)
+func (Zstd) NewWriter(dst io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		return zstd.NewWriter(dst)
+	}
+
+	return zstd.NewWriter(dst, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+}
+
+// NewReader creates a zstd decoder
+func (Zstd) NewReader(src io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoder.IOReadCloser(), nil
+}
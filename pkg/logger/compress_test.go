@@ -0,0 +1,72 @@
+package logger_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressFile(t *testing.T) {
+	for name, compressor := range logger.Compressors {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "log.rotated")
+
+			err := os.WriteFile(name, []byte("Hello World\n"), 0o640)
+			assert.NoError(t, err, "Test writes rotated file")
+
+			err = logger.CompressFile(name, compressor, 0)
+			assert.NoError(t, err, "Compresses rotated file")
+
+			_, err = os.Stat(name)
+			assert.ErrorIs(t, err, os.ErrNotExist, "Removes uncompressed file")
+
+			stat, err := os.Stat(name + compressor.Extension())
+			assert.NoError(t, err, "Stats compressed file")
+			assert.Equal(t, os.FileMode(0o640), stat.Mode(), "Preserves mode bits of the uncompressed file")
+			assert.Equal(t, compressor, logger.CompressorFor(stat.Name()), "Finds compressor by file extension")
+
+			file, err := os.Open(name + compressor.Extension())
+			assert.NoError(t, err, "Opens compressed file")
+
+			defer file.Close()
+
+			reader, err := compressor.NewReader(file)
+			assert.NoError(t, err, "Creates decompressing reader")
+
+			data, err := io.ReadAll(reader)
+			assert.NoError(t, err, "Decompresses file")
+			assert.Equal(t, []byte("Hello World\n"), data)
+			assert.NoError(t, reader.Close())
+
+			entries, err := os.ReadDir(dir)
+			assert.NoError(t, err, "Lists test directory")
+			assert.Len(t, entries, 1, "Does not leave temporary files")
+		})
+	}
+}
+
+func TestCompressLevel(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log.rotated")
+
+	err := os.WriteFile(name, []byte("Hello World\n"), 0o644)
+	assert.NoError(t, err, "Test writes rotated file")
+
+	err = logger.CompressFile(name, logger.Gzip{}, 42)
+	assert.Error(t, err, "Returns error for an invalid level")
+
+	_, err = os.Stat(name)
+	assert.NoError(t, err, "Retains uncompressed file after an error")
+
+	err = logger.CompressFile(name, logger.Zstd{}, 19)
+	assert.NoError(t, err, "Compresses with an explicit level")
+}
+
+func TestCompressMissingFile(t *testing.T) {
+	err := logger.CompressFile(filepath.Join(t.TempDir(), "log.missing"), logger.Gzip{}, 0)
+	assert.ErrorIs(t, err, os.ErrNotExist, "Returns error for a missing file")
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"storj.io/common/memory"
+)
+
+// Config holds the settings of an svlogd `config` file. Pointer fields are nil if a setting is not present
+type Config struct {
+	MaxSize  *memory.Size
+	Count    *int
+	MinCount *int
+	MaxAge   *time.Duration
+
+	Processor *string
+
+	Remote     *string
+	RemoteOnly bool
+	Prefix     *string
+
+	Select Selectors
+}
+
+// ConfigPath returns the path of the svlogd config file in the same directory as an output file, or in a log directory
+func ConfigPath(name string) string {
+	name, _ = ResolvePath(name)
+	return filepath.Join(filepath.Dir(name), "config")
+}
+
+// ReadConfig parses an svlogd config file
+func ReadConfig(name string) (config Config, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if err = config.parse(scanner.Text()); err != nil {
+			return config, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+	}
+
+	return config, scanner.Err()
+}
+
+// parse a single line of an svlogd config file. Unknown settings are ignored, as they are by svlogd
+func (config *Config) parse(line string) (err error) {
+	if line == "" {
+		return
+	}
+
+	value := line[1:]
+
+	switch line[0] {
+	case 's':
+		// Zero disables rotation by size
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if size == 0 {
+			size = math.MaxInt64
+		}
+
+		config.MaxSize = (*memory.Size)(&size)
+
+	case 'n':
+		// Zero disables removal of rotated files
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			count = -1
+		}
+
+		config.Count = &count
+
+	case 'N':
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		config.MinCount = &count
+
+	case 't':
+		// Zero disables rotation by age
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		age := time.Duration(seconds) * time.Second
+		if seconds == 0 {
+			age = math.MaxInt64
+		}
+		config.MaxAge = &age
+
+	case '!':
+		config.Processor = &value
+
+	case 'u', 'U':
+		config.Remote = &value
+		config.RemoteOnly = line[0] == 'U'
+
+	case 'p':
+		config.Prefix = &value
+
+	case '+', '-', 'e', 'E':
+		err = config.Select.Set(line)
+	}
+
+	return
+}
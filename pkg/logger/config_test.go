@@ -0,0 +1,61 @@
+package logger_test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"storj.io/common/memory"
+)
+
+func TestReadConfig(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(name, []byte("s100000\nn5\nN2\nt86400\n!gzip\nU10.0.0.1:514\npweb: \n-*health*\neERROR*\nx unknown\n"), 0o644)
+
+	config, err := logger.ReadConfig(name)
+	assert.NoError(t, err, "Reads config without error")
+
+	assert.Equal(t, memory.Size(100000), *config.MaxSize)
+	assert.Equal(t, 5, *config.Count)
+	assert.Equal(t, 2, *config.MinCount)
+	assert.Equal(t, 24*time.Hour, *config.MaxAge)
+	assert.Equal(t, "gzip", *config.Processor)
+	assert.Equal(t, "10.0.0.1:514", *config.Remote)
+	assert.True(t, config.RemoteOnly)
+	assert.Equal(t, "web: ", *config.Prefix)
+	assert.Equal(t, logger.Selectors{{Action: '-', Pattern: "*health*"}, {Action: 'e', Pattern: "ERROR*"}}, config.Select)
+}
+
+func TestReadConfigDefaults(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(name, []byte("s0\nn0\n"), 0o644)
+
+	config, err := logger.ReadConfig(name)
+	assert.NoError(t, err, "Reads config without error")
+
+	assert.Equal(t, memory.Size(math.MaxInt64), *config.MaxSize, "s0 disables rotation by size")
+	assert.Equal(t, -1, *config.Count, "n0 disables retention by count")
+	assert.Nil(t, config.MaxAge, "Settings are nil if not present")
+	assert.Empty(t, config.Select)
+
+	os.WriteFile(name, []byte("t0\n"), 0o644)
+
+	config, err = logger.ReadConfig(name)
+	assert.NoError(t, err, "Reads config without error")
+	assert.Equal(t, time.Duration(math.MaxInt64), *config.MaxAge, "t0 disables rotation by age")
+}
+
+func TestReadConfigError(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(name, []byte("n5\nsbig\n"), 0o644)
+
+	_, err := logger.ReadConfig(name)
+	assert.Contains(t, fmt.Sprint(err), name+":2:", "Reports the line of an invalid setting")
+
+	assert.Equal(t, filepath.Join("service", "log", "config"), logger.ConfigPath(filepath.Join("service", "log", "current")))
+}
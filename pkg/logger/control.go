@@ -0,0 +1,251 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrNotRunning indicates that no logger process is serving a control socket
+var ErrNotRunning = errors.New("logger is not running")
+
+// ErrUnknownCommand is returned for unsupported control commands
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Listen creates a control socket at the given path, replacing a stale socket left by a previous process
+func Listen(path string) (net.Listener, error) {
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("control socket %s is in use by another process", path)
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the logger's owner may issue commands
+	err = os.Chmod(path, 0o600)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// Serve handles control connections until the context is canceled. The listener is closed, and its socket removed, before returning
+func (rotator *Rotator) Serve(ctx context.Context, listener net.Listener) {
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			rotator.control(ctx, conn)
+		}()
+	}
+}
+
+// control reads a single command line from a connection and writes the command's response
+func (rotator *Rotator) control(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	reader := bufio.NewReader(conn)
+
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+
+	var body bytes.Buffer
+
+	if strings.TrimSpace(line) == "write" {
+		// Copy the remainder of the connection to the output file
+		conn.SetReadDeadline(time.Time{})
+		err = rotator.copy(ctx, reader)
+	} else {
+		err = rotator.Command(&body, strings.Fields(line)...)
+	}
+
+	if err != nil {
+		fmt.Fprintf(conn, "error: %s\n", err)
+		return
+	}
+
+	fmt.Fprintln(conn, "ok")
+	body.WriteTo(conn)
+}
+
+// Command applies a control command to the Rotator, writing any output to out
+func (rotator *Rotator) Command(out io.Writer, args ...string) error {
+	switch command := strings.Join(args, " "); command {
+	case "rotate":
+		rotated, err := rotator.Rotate()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "rotated", rotated)
+
+	case "rotate force":
+		rotated, err := rotator.Force()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "rotated", rotated)
+
+	case "reopen":
+		return rotator.Refresh()
+
+	case "flush":
+		rotator.Flush()
+		return rotator.Sync()
+
+	case "versions":
+		versions, err := rotator.Versions()
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			fmt.Fprintln(out, version)
+		}
+
+	case "status":
+		versions, err := rotator.Versions()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "name", rotator.Name())
+		fmt.Fprintln(out, "size", rotator.Size())
+		fmt.Fprintln(out, "created", rotator.Created().Format(time.RFC3339))
+		fmt.Fprintln(out, "age", rotator.Age().Round(time.Second))
+		fmt.Fprintln(out, "versions", len(versions))
+
+		stats := rotator.ErrorStats()
+		fmt.Fprintln(out, "errors", stats.Errors)
+		fmt.Fprintln(out, "dropped_bytes", stats.DroppedBytes)
+		fmt.Fprintln(out, "dropped_lines", stats.DroppedLines)
+		fmt.Fprintln(out, "buffered_bytes", stats.BufferedBytes)
+
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownCommand, command)
+	}
+
+	return nil
+}
+
+// Control sends a command to a running logger's control socket and returns its output
+func Control(ctx context.Context, path string, args ...string) (_ string, err error) {
+	conn, err := dial(ctx, path)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	_, err = fmt.Fprintln(conn, strings.Join(args, " "))
+	if err != nil {
+		return
+	}
+
+	return response(conn)
+}
+
+// Forward sends input to a running logger's control socket until the source reaches EOF or the context is canceled
+func Forward(ctx context.Context, path string, src io.Reader) (err error) {
+	conn, err := dial(ctx, path)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	_, err = fmt.Fprintln(conn, "write")
+	if err != nil {
+		return
+	}
+
+	_, err = io.Copy(conn, NewCancelReader(ctx, src))
+	if err != nil {
+		return
+	}
+
+	// Signal EOF to the server, then wait for its response
+	err = conn.CloseWrite()
+	if err != nil {
+		return
+	}
+
+	_, err = response(conn)
+	return
+}
+
+func dial(ctx context.Context, path string) (*net.UnixConn, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, fmt.Errorf("%w: %w", ErrNotRunning, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	return conn.(*net.UnixConn), nil
+}
+
+// response reads a status line and body from a control connection
+func response(conn net.Conn) (_ string, err error) {
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		return
+	}
+
+	status, body, _ := strings.Cut(string(data), "\n")
+
+	if message, failed := strings.CutPrefix(status, "error: "); failed {
+		return body, errors.New(message)
+	}
+
+	if status != "ok" {
+		return body, fmt.Errorf("unexpected response from control socket: %q", status)
+	}
+
+	return body, nil
+}
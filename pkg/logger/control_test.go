@@ -0,0 +1,93 @@
+package logger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControl(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	path := logger.RotatorOptions{}.ControlPath(name)
+	assert.Equal(t, name+".sock", path, "Derives control socket path from the output file")
+
+	listener, err := logger.Listen(path)
+	assert.NoError(t, err, "Creates control socket")
+
+	_, err = logger.Listen(path)
+	assert.Error(t, err, "Refuses to replace a live control socket")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		rotator.Serve(ctx, listener)
+		close(done)
+	}()
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	response, err := logger.Control(ctx, path, "status")
+	assert.NoError(t, err, "Sends status command")
+	assert.Contains(t, response, "size 12\n")
+	assert.Contains(t, response, "versions 0\n")
+
+	response, err = logger.Control(ctx, path, "rotate")
+	assert.NoError(t, err, "Sends rotate command")
+	assert.Equal(t, "rotated false\n", response, "Does not rotate below thresholds")
+
+	_, err = logger.Control(ctx, path, "rotate", "force")
+	assert.NoError(t, err, "Sends forced rotate command")
+
+	_, err = logger.Control(ctx, path, "flush")
+	assert.NoError(t, err, "Sends flush command")
+
+	response, err = logger.Control(ctx, path, "status")
+	assert.NoError(t, err, "Sends status command")
+	assert.Contains(t, response, "size 0\n")
+	assert.Contains(t, response, "versions 1\n", "Control socket is not a rotated version")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+
+	response, err = logger.Control(ctx, path, "versions")
+	assert.NoError(t, err, "Sends versions command")
+	assert.Equal(t, versions[0]+"\n", response, "Lists rotated versions")
+
+	listed, err := logger.Versions(ctx, name, logger.RotatorOptions{Control: true})
+	assert.NoError(t, err, "Lists versions through the control socket")
+	assert.Equal(t, versions, listed)
+
+	_, err = logger.Control(ctx, path, "explode")
+	assert.EqualError(t, err, `unknown command: "explode"`, "Returns error for unknown commands")
+
+	cancel()
+	<-done
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist, "Removes control socket")
+
+	_, err = logger.Control(context.Background(), path, "status")
+	assert.ErrorIs(t, err, logger.ErrNotRunning, "Returns ErrNotRunning without a control socket")
+}
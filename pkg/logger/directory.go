@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Names of files in an svlogd log directory
+const (
+	CurrentFile = "current"
+	LockFile    = "lock"
+)
+
+// finished is set on the output file's mode in an svlogd log directory after it has been closed cleanly
+const finished fs.FileMode = 0o100
+
+// versionLabel matches the names of rotated files in an svlogd log directory, with a TAI64N label and a suffix of
+// `s` for files that were closed cleanly or `u` for files that were not
+var versionLabel = regexp.MustCompile(`^@[0-9a-f]{24}\.[su]$`)
+
+// ResolvePath returns the path of the output file for a log path. If the path is a directory, the output file is
+// its svlogd-style `current` file
+func ResolvePath(path string) (name string, directory bool) {
+	if stat, err := os.Stat(path); err == nil && stat.IsDir() {
+		return filepath.Join(path, CurrentFile), true
+	}
+
+	return path, false
+}
+
+// versionGlob returns a pattern matching candidate rotated files of the named output file. listVersions filters
+// matches by name
+func (opts RotatorOptions) versionGlob(name string) string {
+	if opts.Directory {
+		return filepath.Join(filepath.Dir(opts.VersionPrefix(name)), "@*")
+	}
+
+	return opts.VersionPrefix(name) + ".*"
+}
+
+// directoryVersionName returns a name for a file rotated from an output file in an svlogd log directory
+func directoryVersionName(name, suffix string) string {
+	return filepath.Join(filepath.Dir(name), string(AppendTAI64N(nil, time.Now()))+suffix)
+}
+
+// isDirectoryVersion checks if a file name matches rotated files in an svlogd log directory
+func isDirectoryVersion(version string) bool {
+	return versionLabel.MatchString(TrimCompression(filepath.Base(version)))
+}
+
+// directoryVersionTime parses the TAI64N label of a rotated file in an svlogd log directory
+func directoryVersionTime(version string) (time.Time, error) {
+	label, _, _ := strings.Cut(filepath.Base(version), ".")
+	return ParseTAI64N(label)
+}
+
+// salvage moves an output file in an svlogd log directory that was not closed cleanly to a `.u` version named after
+// prefix, so that a new output file is started
+func salvage(name, prefix string) (string, error) {
+	stat, err := os.Stat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil || stat.Mode()&finished != 0 || stat.Size() == 0 {
+		return "", err
+	}
+
+	version := directoryVersionName(prefix, ".u")
+	return version, moveFile(name, version)
+}
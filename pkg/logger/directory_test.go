@@ -0,0 +1,158 @@
+package logger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatorDirectory(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current")
+
+	// Unrelated files in the log directory are not versions
+	os.WriteFile(filepath.Join(dir, "config"), []byte("n2\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "@notes.txt"), nil, 0o644)
+
+	rotator, err := logger.Open(dir, logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+	assert.True(t, rotator.Directory, "Detects a log directory")
+	assert.Equal(t, current, rotator.Name(), "Writes to the current file")
+	assert.FileExists(t, filepath.Join(dir, "lock"), "Locks the log directory")
+	assert.Equal(t, filepath.Join(dir, "current.sock"), rotator.ControlPath(dir), "Derives the control socket path from the current file")
+	assert.Equal(t, filepath.Join(dir, "config"), logger.ConfigPath(dir), "Reads config from the log directory")
+
+	before := time.Now()
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	rotated, err := rotator.Force()
+	assert.NoError(t, err, "Rotate without error")
+	assert.True(t, rotated)
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Lists rotated files")
+	assert.Regexp(t, `/@[0-9a-f]{24}\.s$`, versions[0], "Names rotated files by TAI64N label")
+
+	timestamp, err := rotator.VersionTime(versions[0])
+	assert.NoError(t, err, "Parses version time without error")
+	assert.WithinDuration(t, before, timestamp, time.Second, "Parses the TAI64N label")
+
+	stat, err := os.Stat(versions[0])
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o744), stat.Mode().Perm(), "Marks rotated files as finished")
+
+	stat, err = os.Stat(current)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), stat.Mode().Perm(), "Current file is unfinished while open")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	stat, err = os.Stat(current)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o744), stat.Mode().Perm(), "Marks the current file as finished on close")
+}
+
+func TestRotatorDirectorySalvage(t *testing.T) {
+	dir := t.TempDir()
+
+	// A current file that was not closed cleanly
+	os.WriteFile(filepath.Join(dir, "current"), []byte("Hello world\n"), 0o644)
+
+	rotator, err := logger.Open(dir, logger.RotatorOptions{Count: 2, CreateMode: 0o644})
+	assert.NoError(t, err, "Rotator created without error")
+
+	assert.Zero(t, rotator.Size(), "Starts a new current file")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Salvages the unfinished current file")
+	assert.Regexp(t, `/@[0-9a-f]{24}\.u$`, versions[0], "Names salvaged files with the u suffix")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	// A current file that was closed cleanly is appended
+	os.WriteFile(filepath.Join(dir, "current"), []byte("Hello world\n"), 0o744)
+
+	rotator, err = logger.Open(dir, logger.RotatorOptions{Count: 2, CreateMode: 0o644})
+	assert.NoError(t, err, "Rotator created without error")
+	assert.Equal(t, int64(12), rotator.Size(), "Appends to a finished current file")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestVersionsReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	version := filepath.Join(dir, "@400000006553f10000000000.s")
+
+	// An unfinished current file is not salvaged by listing
+	os.WriteFile(filepath.Join(dir, "current"), []byte("Hello world\n"), 0o644)
+	os.WriteFile(version, []byte("Hello world\n"), 0o744)
+
+	snapshot := func() map[string]os.FileMode {
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+
+		modes := make(map[string]os.FileMode)
+		for _, entry := range entries {
+			info, err := entry.Info()
+			assert.NoError(t, err)
+
+			modes[entry.Name()] = info.Mode()
+		}
+
+		return modes
+	}
+
+	before := snapshot()
+
+	versions, err := logger.Versions(context.Background(), dir, logger.RotatorOptions{})
+	assert.NoError(t, err, "Lists versions without error")
+	assert.Equal(t, []string{version}, versions)
+	assert.Equal(t, before, snapshot(), "Log directory is unchanged after listing")
+
+	// A missing output file is not created
+	name := filepath.Join(dir, "other.log")
+
+	versions, err = logger.Versions(context.Background(), name, logger.RotatorOptions{Pattern: "%Y-%m-%d"})
+	assert.NoError(t, err, "Lists versions without error")
+	assert.Empty(t, versions)
+	assert.Equal(t, before, snapshot(), "Does not create the output file or its lock")
+
+	// Versions are listed while another Rotator holds the lock
+	rotator, err := logger.Open(dir, logger.RotatorOptions{Count: 2, CreateMode: 0o644})
+	assert.NoError(t, err, "Rotator created without error")
+
+	versions, err = logger.Versions(context.Background(), dir, logger.RotatorOptions{})
+	assert.NoError(t, err, "Lists versions without taking the lock")
+	assert.Len(t, versions, 2, "Lists the salvaged version")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestParseTAI64N(t *testing.T) {
+	timestamp, err := logger.ParseTAI64N("@400000000000000a00000000")
+	assert.NoError(t, err)
+	assert.True(t, time.Unix(0, 0).Equal(timestamp), "Parses the unix epoch")
+
+	when := time.Unix(1760666280, 1000)
+	timestamp, err = logger.ParseTAI64N(string(logger.AppendTAI64N(nil, when)))
+	assert.NoError(t, err)
+	assert.True(t, when.Equal(timestamp), "Parses formatted labels")
+
+	_, err = logger.ParseTAI64N("@4000")
+	assert.Error(t, err, "Rejects short labels")
+}
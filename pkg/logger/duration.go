@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration extends time.Duration with the pflag.Value interface, adding the units d (days) and w (weeks)
+type Duration time.Duration
+
+// Day and Week duration units
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// Set value from a string argument
+func (duration *Duration) Set(value string) (err error) {
+	for suffix, unit := range map[string]time.Duration{"d": Day, "w": Week} {
+		if count, found := strings.CutSuffix(value, suffix); found {
+			parsed, err := strconv.ParseFloat(count, 64)
+			if err != nil {
+				return err
+			}
+
+			*duration = Duration(parsed * float64(unit))
+			return nil
+		}
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return
+	}
+
+	*duration = Duration(parsed)
+	return
+}
+
+func (duration Duration) String() string {
+	if duration != 0 && time.Duration(duration)%Day == 0 {
+		return strconv.FormatInt(int64(time.Duration(duration)/Day), 10) + "d"
+	}
+
+	return time.Duration(duration).String()
+}
+
+// Type description for CLI usage
+func (Duration) Type() string {
+	return "duration"
+}
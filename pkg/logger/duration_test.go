@@ -0,0 +1,32 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"30d":   30 * logger.Day,
+		"1.5d":  36 * time.Hour,
+		"2w":    2 * logger.Week,
+		"12h":   12 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	} {
+		var duration logger.Duration
+
+		assert.NoError(t, duration.Set(value), "Parses %q", value)
+		assert.Equal(t, expected, time.Duration(duration), "Parses %q", value)
+	}
+
+	var duration logger.Duration
+	assert.Error(t, duration.Set("xd"), "Rejects invalid day count")
+	assert.Error(t, duration.Set("30"), "Rejects missing unit")
+
+	assert.Equal(t, "30d", logger.Duration(30*logger.Day).String())
+	assert.Equal(t, "36h0m0s", logger.Duration(36*time.Hour).String())
+	assert.Equal(t, "0s", logger.Duration(0).String())
+}
@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrorPolicy selects the behavior of Pipe when writing to the output file fails
+type ErrorPolicy string
+
+// Supported ErrorPolicy values
+const (
+	// ErrorExit returns the write error from Pipe
+	ErrorExit ErrorPolicy = "exit"
+
+	// ErrorDrop discards input that could not be written
+	ErrorDrop ErrorPolicy = "drop"
+
+	// ErrorBuffer holds input that could not be written in memory, up to ErrorBufferSize, until a write succeeds
+	ErrorBuffer ErrorPolicy = "buffer"
+
+	// ErrorRetry blocks input and retries writes with an exponential backoff
+	ErrorRetry ErrorPolicy = "retry"
+)
+
+// Bounds for the backoff of ErrorRetry writes and of rotations retried by Watch
+const (
+	RetryMinBackoff = 100 * time.Millisecond
+	RetryMaxBackoff = 30 * time.Second
+)
+
+// Set value from a string argument
+func (policy *ErrorPolicy) Set(value string) error {
+	switch ErrorPolicy(value) {
+	case ErrorExit, ErrorDrop, ErrorBuffer, ErrorRetry:
+		*policy = ErrorPolicy(value)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported error policy %q", value)
+}
+
+func (policy ErrorPolicy) String() string {
+	return string(policy)
+}
+
+// Type description for CLI usage
+func (ErrorPolicy) Type() string {
+	return "exit|drop|buffer|retry"
+}
+
+// ErrorStats counts write errors and the input affected by them
+type ErrorStats struct {
+	Errors        int64
+	DroppedBytes  int64
+	DroppedLines  int64
+	BufferedBytes int64
+}
+
+// backlog holds input that could not be written under the ErrorBuffer policy
+type backlog struct {
+	sync.Mutex
+
+	chunks [][]byte
+	stats  ErrorStats
+}
+
+// ErrorStats returns a snapshot of the Rotator's write error counters
+func (rotator *Rotator) ErrorStats() ErrorStats {
+	rotator.backlog.Lock()
+	defer rotator.backlog.Unlock()
+
+	return rotator.backlog.stats
+}
+
+// deliver writes a chunk of input to the output file, applying the OnError policy to write errors
+func (rotator *Rotator) deliver(ctx context.Context, chunk []byte) error {
+	switch rotator.Options().OnError {
+	case ErrorDrop:
+		rest, err := rotator.write(chunk)
+		rotator.fail(err, rest)
+
+	case ErrorBuffer:
+		rotator.backlog.Lock()
+		defer rotator.backlog.Unlock()
+
+		// Preserve ordering: new input is only written after the backlog has been flushed
+		if rotator.flush() {
+			var err error
+
+			chunk, err = rotator.write(chunk)
+			if err != nil {
+				rotator.backlog.stats.Errors++
+			}
+		}
+
+		rotator.hold(chunk)
+
+	case ErrorRetry:
+		backoff := RetryMinBackoff
+
+		for {
+			rest, err := rotator.write(chunk)
+			rotator.fail(err, nil)
+
+			if len(rest) == 0 {
+				return nil
+			}
+
+			chunk = rest
+
+			select {
+			case <-ctx.Done():
+				rotator.fail(nil, chunk)
+				return nil
+			case <-time.After(backoff):
+			}
+
+			backoff = min(backoff*2, RetryMaxBackoff)
+		}
+
+	default:
+		_, err := rotator.writeLines(chunk)
+		return err
+	}
+
+	return nil
+}
+
+// write returns the unwritten remainder of a chunk after an error. Errors from rotation after a successful write
+// return an empty remainder
+func (rotator *Rotator) write(chunk []byte) ([]byte, error) {
+	n, err := rotator.writeLines(chunk)
+	return chunk[n:], err
+}
+
+// fail counts a write error and input dropped because of it
+func (rotator *Rotator) fail(err error, dropped []byte) {
+	if err == nil && len(dropped) == 0 {
+		return
+	}
+
+	rotator.backlog.Lock()
+	defer rotator.backlog.Unlock()
+
+	if err != nil {
+		rotator.backlog.stats.Errors++
+	}
+
+	rotator.backlog.stats.DroppedBytes += int64(len(dropped))
+	rotator.backlog.stats.DroppedLines += int64(bytes.Count(dropped, []byte{'\n'}))
+}
+
+// hold appends a copy of a chunk to the backlog, dropping it if the backlog would exceed ErrorBufferSize. The
+// caller must hold the backlog's lock
+func (rotator *Rotator) hold(chunk []byte) {
+	if len(chunk) == 0 {
+		return
+	}
+
+	stats := &rotator.backlog.stats
+
+	if stats.BufferedBytes+int64(len(chunk)) > int64(rotator.ErrorBufferSize) {
+		stats.DroppedBytes += int64(len(chunk))
+		stats.DroppedLines += int64(bytes.Count(chunk, []byte{'\n'}))
+		return
+	}
+
+	rotator.backlog.chunks = append(rotator.backlog.chunks, bytes.Clone(chunk))
+	stats.BufferedBytes += int64(len(chunk))
+}
+
+// flush writes buffered chunks in order, returning true if the backlog is empty. The caller must hold the backlog's lock
+func (rotator *Rotator) flush() bool {
+	for len(rotator.backlog.chunks) > 0 {
+		chunk := rotator.backlog.chunks[0]
+
+		n, err := rotator.writeLines(chunk)
+		rotator.backlog.stats.BufferedBytes -= int64(n)
+
+		if err != nil {
+			rotator.backlog.stats.Errors++
+
+			if n < len(chunk) {
+				rotator.backlog.chunks[0] = chunk[n:]
+				return false
+			}
+		}
+
+		rotator.backlog.chunks = rotator.backlog.chunks[1:]
+	}
+
+	return true
+}
+
+// Flush writes input held by the ErrorBuffer policy to the output file
+func (rotator *Rotator) Flush() bool {
+	rotator.backlog.Lock()
+	defer rotator.backlog.Unlock()
+
+	return rotator.flush()
+}
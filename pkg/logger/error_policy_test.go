@@ -0,0 +1,301 @@
+package logger_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// FailingWriter returns EIO from the first $failures calls to Write
+type FailingWriter struct {
+	logger.WriteRotator
+	failures atomic.Int32
+}
+
+func (writer *FailingWriter) Write(buf []byte) (int, error) {
+	if writer.failures.Add(-1) >= 0 {
+		return 0, syscall.EIO
+	}
+
+	return writer.WriteRotator.Write(buf)
+}
+
+// FailingReopen returns EACCES from the first $failures calls to Reopen
+type FailingReopen struct {
+	logger.WriteRotator
+	failures atomic.Int32
+}
+
+func (writer *FailingReopen) Reopen(name string, mode fs.FileMode) error {
+	if writer.failures.Add(-1) >= 0 {
+		return syscall.EACCES
+	}
+
+	return writer.WriteRotator.Reopen(name, mode)
+}
+
+// FullWriter returns ENOSPC from the first $failures calls to Write
+type FullWriter struct {
+	logger.WriteRotator
+	failures atomic.Int32
+}
+
+func (writer *FullWriter) Write(buf []byte) (int, error) {
+	if writer.failures.Add(-1) >= 0 {
+		return 0, syscall.ENOSPC
+	}
+
+	return writer.WriteRotator.Write(buf)
+}
+
+func NewFailingRotator(t *testing.T, policy logger.ErrorPolicy, failures int32) *logger.Rotator {
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+		Count:           2,
+		CreateMode:      0o644,
+		OnError:         policy,
+		ErrorBufferSize: 8,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	writer := &FailingWriter{WriteRotator: rotator.WriteRotator}
+	writer.failures.Store(failures)
+
+	rotator.WriteRotator = writer
+	return rotator
+}
+
+func TestErrorExit(t *testing.T) {
+	rotator := NewFailingRotator(t, logger.ErrorExit, 1)
+
+	err := rotator.Pipe(context.Background(), strings.NewReader("Hello world\n"))
+	assert.ErrorIs(t, err, syscall.EIO, "Returns write error")
+}
+
+func TestErrorDrop(t *testing.T) {
+	rotator := NewFailingRotator(t, logger.ErrorDrop, 1)
+
+	err := rotator.Pipe(context.Background(), strings.NewReader("Hello\nworld\n"))
+	assert.NoError(t, err, "Drops input after write error")
+
+	assert.Equal(t, logger.ErrorStats{Errors: 1, DroppedBytes: 12, DroppedLines: 2}, rotator.ErrorStats())
+}
+
+func TestErrorBuffer(t *testing.T) {
+	rotator := NewFailingRotator(t, logger.ErrorBuffer, 2)
+	reader, writer := io.Pipe()
+	done := make(chan error)
+
+	go func() { done <- rotator.Pipe(context.Background(), reader) }()
+
+	writer.Write([]byte("Hello\n"))
+
+	assert.Eventually(t, func() bool {
+		return rotator.ErrorStats().BufferedBytes == 6
+	}, time.Second, 10*time.Millisecond, "Buffers input after write error")
+
+	writer.Write([]byte("cruel\n"))
+
+	assert.Eventually(t, func() bool {
+		return rotator.ErrorStats().DroppedBytes == 6
+	}, time.Second, 10*time.Millisecond, "Drops input that exceeds the buffer size")
+
+	writer.Write([]byte("world\n"))
+	writer.Close()
+
+	assert.NoError(t, <-done, "Pipe returns without error at EOF")
+	assert.Equal(t, logger.ErrorStats{Errors: 2, DroppedBytes: 6, DroppedLines: 1}, rotator.ErrorStats())
+
+	data, err := os.ReadFile(rotator.Name())
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, []byte("Hello\nworld\n"), data, "Writes buffered input in order")
+}
+
+func TestErrorRetry(t *testing.T) {
+	rotator := NewFailingRotator(t, logger.ErrorRetry, 2)
+
+	err := rotator.Pipe(context.Background(), strings.NewReader("Hello world\n"))
+	assert.NoError(t, err, "Retries write errors")
+	assert.Equal(t, logger.ErrorStats{Errors: 2}, rotator.ErrorStats())
+
+	data, err := os.ReadFile(rotator.Name())
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, []byte("Hello world\n"), data)
+}
+
+func TestErrorNoSpace(t *testing.T) {
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		MinFree:    1,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	_, err = rotator.Force()
+	assert.NoError(t, err, "Force without error")
+
+	_, err = rotator.Write([]byte("Hello again\n"))
+	assert.NoError(t, err, "Write without error")
+
+	writer := &FullWriter{WriteRotator: rotator.WriteRotator}
+	rotator.WriteRotator = writer
+
+	// The first full write removes the only version
+	writer.failures.Store(1)
+
+	n, err := rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Retries write after reclaiming space")
+	assert.Equal(t, 12, n, "Write returns correct byte-length")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Empty(t, versions, "Removes versions to reclaim space")
+
+	data, err := os.ReadFile(rotator.Name())
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, "Hello again\nHello world\n", string(data), "Appends the retried write")
+
+	// Without versions, the output file is truncated
+	writer.failures.Store(1)
+
+	_, err = rotator.Write([]byte("Goodbye\n"))
+	assert.NoError(t, err, "Retries write after truncating the output file")
+
+	data, err = os.ReadFile(rotator.Name())
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, "Goodbye\n", string(data), "Truncates the output file as a last resort")
+
+	// Without MinFree, the error is returned
+	assert.NoError(t, rotator.Reconfigure(logger.RotatorOptions{Enabled: true, MaxSize: 1024, MaxAge: time.Hour, Count: -1, CreateMode: 0o644}))
+	writer.failures.Store(1)
+
+	_, err = rotator.Write([]byte("Goodbye\n"))
+	assert.ErrorIs(t, err, syscall.ENOSPC, "Returns ENOSPC without MinFree")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestErrorPolicyRotation(t *testing.T) {
+	for _, policy := range []logger.ErrorPolicy{logger.ErrorDrop, logger.ErrorExit} {
+		t.Run(string(policy), func(t *testing.T) {
+			alerts := new(strings.Builder)
+
+			rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+				Enabled:    true,
+				MaxSize:    1024,
+				MaxAge:     time.Millisecond,
+				Count:      2,
+				Pattern:    "%Y-%m-%dT%H%M%S.%f",
+				CreateMode: 0o644,
+				OnError:    policy,
+				Alerts:     alerts,
+			})
+
+			assert.NoError(t, err, "Rotator created without error")
+
+			writer := &FailingReopen{WriteRotator: rotator.WriteRotator}
+			writer.failures.Store(2)
+
+			rotator.WriteRotator = writer
+
+			// Write without checking for rotation, so that only Watch rotates the output file
+			_, err = writer.WriteRotator.Write([]byte("Hello world\n"))
+			assert.NoError(t, err, "Write without error")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+
+			go func() { done <- rotator.Watch(ctx, 10*time.Millisecond) }()
+
+			if policy == logger.ErrorExit {
+				assert.ErrorIs(t, <-done, syscall.EACCES, "Watch returns rotation errors under the exit policy")
+				cancel()
+
+				assert.NoError(t, rotator.Close(), "Rotator closed without error")
+				return
+			}
+
+			assert.Eventually(t, func() bool {
+				versions, err := rotator.Versions()
+				return err == nil && len(versions) == 1
+			}, time.Second, 10*time.Millisecond, "Retries rotation on the next check")
+
+			cancel()
+			assert.NoError(t, <-done, "Watch does not return rotation errors")
+
+			assert.Equal(t, int64(2), rotator.ErrorStats().Errors, "Counts rotation errors")
+			assert.Contains(t, alerts.String(), "unable to rotate", "Reports rotation errors to Alerts")
+
+			assert.NoError(t, rotator.Close(), "Rotator closed without error")
+		})
+	}
+}
+
+func TestErrorPolicyScheduleBackoff(t *testing.T) {
+	schedule, err := logger.ParseSchedule("@every 1s")
+	assert.NoError(t, err, "Parses schedule")
+
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		Count:      2,
+		RotateAt:   schedule,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		OnError:    logger.ErrorDrop,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	writer := &FailingReopen{WriteRotator: rotator.WriteRotator}
+	writer.failures.Store(math.MaxInt32)
+
+	rotator.WriteRotator = writer
+
+	_, err = writer.WriteRotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- rotator.Watch(ctx, 0) }()
+
+	// The boundary stays in the past while rotation fails
+	time.Sleep(1500 * time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done, "Watch does not return rotation errors")
+
+	errors := rotator.ErrorStats().Errors
+	assert.Positive(t, errors, "Counts rotation errors")
+	assert.LessOrEqual(t, errors, int64(5), "Backs off retries after a missed boundary")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestErrorPolicyFlag(t *testing.T) {
+	var policy logger.ErrorPolicy
+
+	assert.NoError(t, policy.Set("buffer"))
+	assert.Equal(t, logger.ErrorBuffer, policy)
+	assert.Error(t, policy.Set("ignore"), "Rejects unsupported policy")
+}
@@ -80,11 +80,22 @@ func (writer *FileWriter) Open(name string, mode fs.FileMode) (err error) {
 	return
 }
 
-// Reopen rotates the output file by closing the existing handle, renaming the file, then creating a new file at the same path
+// Reopen rotates the output file by closing the existing handle, renaming the file, then creating a new file at the same path.
+// An existing file at the renamed path is never overwritten
 func (writer *FileWriter) Reopen(rename string, mode fs.FileMode) (err error) {
 	writer.Lock()
 	defer writer.Unlock()
 
+	// Check before closing so that the output file remains open if the rotated path is taken
+	_, err = os.Lstat(rename)
+	if err == nil {
+		return &fs.PathError{Op: "rotate", Path: rename, Err: fs.ErrExist}
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+
 	err = writer.file.Sync()
 	if err != nil {
 		return
@@ -103,6 +114,24 @@ func (writer *FileWriter) Reopen(rename string, mode fs.FileMode) (err error) {
 	return writer.create(writer.file.Name(), mode)
 }
 
+// Refresh closes the output file then opens the same path again, creating a new file if the previous one was moved or removed
+func (writer *FileWriter) Refresh(mode fs.FileMode) (err error) {
+	writer.Lock()
+	defer writer.Unlock()
+
+	err = writer.file.Sync()
+	if err != nil {
+		return
+	}
+
+	err = writer.file.Close()
+	if err != nil {
+		return
+	}
+
+	return writer.Open(writer.file.Name(), mode)
+}
+
 // Truncate closes and reopens the output file with the TRUNCATE flag set
 func (writer *FileWriter) Truncate() (err error) {
 	writer.Lock()
@@ -118,10 +147,21 @@ func (writer *FileWriter) Truncate() (err error) {
 		return
 	}
 
+	writer.size = 0
+	writer.created = time.Now().UTC()
+
 	writer.file, err = os.OpenFile(writer.file.Name(), os.O_WRONLY|os.O_TRUNC, 0)
 	return
 }
 
+// Sync commits the output file's contents to stable storage
+func (writer *FileWriter) Sync() error {
+	writer.Lock()
+	defer writer.Unlock()
+
+	return writer.file.Sync()
+}
+
 // Close the underlying file
 func (writer *FileWriter) Close() (err error) {
 	err = multierr.Append(err, writer.file.Sync())
@@ -142,8 +182,10 @@ func (writer *FileWriter) Created() time.Time {
 	return writer.created
 }
 
-// Name returns the name of the file as presented to Open
+// Name is a synchronized getter for the name of the file as presented to Open
 func (writer *FileWriter) Name() string {
+	writer.RLock()
+	defer writer.RUnlock()
 	return writer.file.Name()
 }
 
@@ -82,6 +82,27 @@ func TestFileReopen(t *testing.T) {
 	assert.Empty(t, data, "File is empty")
 }
 
+func TestFileReopenExisting(t *testing.T) {
+	dir, writer := NewFileWriterBench(t)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "log.rotated"), []byte("Rotated\n"), 0o644))
+
+	err := writer.Reopen(filepath.Join(dir, "log.rotated"), 0o644)
+	assert.ErrorIs(t, err, os.ErrExist, "Refuses to overwrite an existing file")
+
+	data, err := os.ReadFile(filepath.Join(dir, "log.rotated"))
+	assert.NoError(t, err, "Test reads back existing file")
+	assert.Equal(t, []byte("Rotated\n"), data, "Existing file is unchanged")
+
+	n, err := writer.Write([]byte("Hello Again\n"))
+	assert.NoError(t, err, "Output file remains open")
+	assert.Equal(t, 12, n)
+
+	data, err = os.ReadFile(filepath.Join(dir, "log"))
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, []byte("Hello World\nHello Again\n"), data)
+}
+
 func TestFileAppend(t *testing.T) {
 	dir, writer0 := NewFileWriterBench(t)
 
@@ -106,3 +127,28 @@ func TestFileAppend(t *testing.T) {
 	assert.Equal(t, 24, len(data))
 	assert.Equal(t, []byte("Hello World\nHello World\n"), data)
 }
+
+func TestFileRefresh(t *testing.T) {
+	dir, writer := NewFileWriterBench(t)
+
+	err := os.Rename(filepath.Join(dir, "log"), filepath.Join(dir, "log.moved"))
+	assert.NoError(t, err, "Test moves output file")
+
+	err = writer.Refresh(0o644)
+	assert.NoError(t, err, "Reopens output file")
+	assert.Zero(t, writer.Size(), "Resets size for a new output file")
+
+	n, err := writer.Write([]byte("Hello World\n"))
+	assert.NoError(t, err, "Writes to reopened output file")
+	assert.Equal(t, 12, n)
+
+	assert.NoError(t, writer.Close(), "Syncs and closes output file")
+
+	data, err := os.ReadFile(filepath.Join(dir, "log"))
+	assert.NoError(t, err, "Test reads back reopened file")
+	assert.Equal(t, []byte("Hello World\n"), data)
+
+	data, err = os.ReadFile(filepath.Join(dir, "log.moved"))
+	assert.NoError(t, err, "Test reads back moved file")
+	assert.Equal(t, []byte("Hello World\n"), data, "Moved file is not written after refresh")
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// RotateStage identifies the point of a rotation at which a RotateEvent is emitted
+type RotateStage int
+
+// RotateEvent stages
+const (
+	// BeforeRotate is emitted before the output file is renamed or truncated
+	BeforeRotate RotateStage = iota
+
+	// AfterRotate is emitted after the output file is renamed or truncated, or fails to be
+	AfterRotate
+
+	// AfterArchive is emitted after rotated versions are processed, compressed and cleaned up
+	AfterArchive
+)
+
+func (stage RotateStage) String() string {
+	switch stage {
+	case BeforeRotate:
+		return "before-rotate"
+	case AfterRotate:
+		return "after-rotate"
+	case AfterArchive:
+		return "after-archive"
+	}
+
+	return "unknown"
+}
+
+// RotateReason describes the cause of a rotation
+type RotateReason string
+
+// RotateReason values
+const (
+	ReasonSize     RotateReason = "size"
+	ReasonAge      RotateReason = "age"
+	ReasonSchedule RotateReason = "schedule"
+	ReasonForced   RotateReason = "forced"
+)
+
+// RotateEvent describes a rotation of the output file to OnRotate hooks
+type RotateEvent struct {
+	Stage  RotateStage
+	Reason RotateReason
+
+	// OldName is the path of the output file. NewName is the path of the rotated version, or empty if the output
+	// file is truncated in place. With ArchiveDir or NamingNumeric, the version is moved to NewName before
+	// archiving. After archiving, NewName includes a compression extension if the version was compressed
+	OldName string
+	NewName string
+
+	// Size and Age of the output file before rotation
+	Size int64
+	Age  time.Duration
+
+	// Err is the error from renaming or truncating the output file after rotation, or from moving the version to
+	// NewName and cleanup after archiving
+	Err error
+}
+
+// OnRotate registers a hook that is called at each RotateStage of every rotation. Hooks are called synchronously:
+// BeforeRotate and AfterRotate hooks block writes to the output file and must not call Rotator methods that rotate
+// or reopen it. The context passed to hooks is canceled when the Rotator is closed
+func (rotator *Rotator) OnRotate(hook func(context.Context, RotateEvent)) {
+	rotator.settings.Lock()
+	defer rotator.settings.Unlock()
+
+	rotator.hooks = append(rotator.hooks, hook)
+}
+
+// emit calls OnRotate hooks with an event
+func (rotator *Rotator) emit(event RotateEvent) {
+	rotator.settings.RLock()
+	hooks := rotator.hooks
+	rotator.settings.RUnlock()
+
+	for _, hook := range hooks {
+		hook(rotator.ctx, event)
+	}
+}
+
+// archived returns the current name of a rotated version, which may have been compressed
+func archived(version string) string {
+	if _, err := os.Stat(version); err == nil {
+		return version
+	}
+
+	for _, compressor := range Compressors {
+		if _, err := os.Stat(version + compressor.Extension()); err == nil {
+			return version + compressor.Extension()
+		}
+	}
+
+	return version
+}
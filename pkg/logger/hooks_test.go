@@ -0,0 +1,97 @@
+package logger_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatorOnRotate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log")
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    12,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Compress:   "gzip",
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	var (
+		lock   sync.Mutex
+		events []logger.RotateEvent
+		ctx    context.Context
+	)
+
+	rotator.OnRotate(func(hookCtx context.Context, event logger.RotateEvent) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		ctx = hookCtx
+		events = append(events, event)
+	})
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	// Close waits for background archiving
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Len(t, events, 3, "Emits an event for each stage")
+	assert.Equal(t, []logger.RotateStage{logger.BeforeRotate, logger.AfterRotate, logger.AfterArchive},
+		[]logger.RotateStage{events[0].Stage, events[1].Stage, events[2].Stage}, "Emits stages in order")
+
+	for _, event := range events {
+		assert.Equal(t, logger.ReasonSize, event.Reason, "Reports the reason for rotation")
+		assert.Equal(t, name, event.OldName, "Reports the output file name")
+		assert.Equal(t, int64(12), event.Size, "Reports the size of the output file before rotation")
+		assert.NoError(t, event.Err)
+	}
+
+	assert.Equal(t, events[0].NewName, events[1].NewName, "Reports the rotated version")
+	assert.Equal(t, events[1].NewName+".gz", events[2].NewName, "Reports the compressed version after archiving")
+
+	assert.Error(t, ctx.Err(), "Hook context is canceled by Close")
+}
+
+func TestRotatorOnRotateForced(t *testing.T) {
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      0,
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	reasons := make(chan logger.RotateEvent, 3)
+	rotator.OnRotate(func(_ context.Context, event logger.RotateEvent) { reasons <- event })
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	rotated, err := rotator.Force()
+	assert.NoError(t, err, "Force without error")
+	assert.True(t, rotated)
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	for i := 0; i < 3; i++ {
+		event := <-reasons
+		assert.Equal(t, logger.ReasonForced, event.Reason, "Reports forced rotation")
+		assert.Empty(t, event.NewName, "Truncation has no rotated version")
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+)
+
+// LineFramer splits input into whole lines so that the output file is only written, and rotated, at newline
+// boundaries. Lines longer than a maximum length are split into multiple lines
+type LineFramer struct {
+	max    int
+	stamp  func([]byte) []byte
+	filter func([]byte) bool
+
+	pending []byte
+	framed  []byte
+
+	// Prefixes for lines beginning in the current input, and for the pending partial line
+	arrival []byte
+	started []byte
+}
+
+// NewLineFramer creates a LineFramer for lines up to max bytes, excluding the newline. Zero disables the limit.
+// If stamp is not nil, it appends a prefix for lines that begin in each input to its argument. If filter is not
+// nil, lines are only returned if it selects their content
+func NewLineFramer(max int, stamp func([]byte) []byte, filter func([]byte) bool) *LineFramer {
+	return &LineFramer{max: max, stamp: stamp, filter: filter}
+}
+
+// Frame appends input to the pending partial line and returns all complete lines. The returned slice is only
+// valid until the next call to Frame or Rest
+func (framer *LineFramer) Frame(input []byte) []byte {
+	framer.framed = framer.framed[:0]
+
+	if len(input) == 0 {
+		return framer.framed
+	}
+
+	if framer.stamp != nil {
+		framer.arrival = framer.stamp(framer.arrival[:0])
+	}
+
+	if len(framer.pending) == 0 {
+		framer.started = append(framer.started[:0], framer.arrival...)
+	}
+
+	framer.pending = append(framer.pending, input...)
+	rest := framer.pending
+
+	for len(rest) > 0 {
+		end := bytes.IndexByte(rest, '\n')
+
+		if framer.max > 0 && (end > framer.max || (end < 0 && len(rest) > framer.max)) {
+			// Split runaway or binary input into lines of the maximum length
+			framer.line(rest[:framer.max])
+			rest = rest[framer.max:]
+			continue
+		}
+
+		if end < 0 {
+			break
+		}
+
+		framer.line(rest[:end])
+		rest = rest[end+1:]
+	}
+
+	framer.pending = append(framer.pending[:0], rest...)
+
+	return framer.framed
+}
+
+// Rest returns the pending partial line terminated with a newline, or nil if there is no selected partial line
+func (framer *LineFramer) Rest() []byte {
+	if len(framer.pending) == 0 {
+		return nil
+	}
+
+	framer.framed = framer.framed[:0]
+	framer.line(framer.pending)
+	framer.pending = framer.pending[:0]
+
+	if len(framer.framed) == 0 {
+		return nil
+	}
+
+	return framer.framed
+}
+
+// line appends a prefixed and newline-terminated line to the framed output if it is selected by the filter
+func (framer *LineFramer) line(content []byte) {
+	if framer.filter == nil || framer.filter(content) {
+		framer.framed = append(framer.framed, framer.started...)
+		framer.framed = append(framer.framed, content...)
+		framer.framed = append(framer.framed, '\n')
+	}
+
+	// The next line begins in the current input
+	framer.started = append(framer.started[:0], framer.arrival...)
+}
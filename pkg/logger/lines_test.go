@@ -0,0 +1,43 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineFramer(t *testing.T) {
+	framer := logger.NewLineFramer(16, nil, nil)
+
+	assert.Empty(t, framer.Frame([]byte("Hello")), "Holds a partial line")
+	assert.Equal(t, "Hello world\n", string(framer.Frame([]byte(" world\nfoo"))), "Returns complete lines")
+	assert.Equal(t, "foo\nbar\n", string(framer.Frame([]byte("\nbar\nbaz"))), "Returns multiple lines")
+	assert.Equal(t, "baz\n", string(framer.Rest()), "Terminates the partial line with a newline")
+	assert.Nil(t, framer.Rest(), "Returns nil without a partial line")
+}
+
+func TestLineFramerMaxLength(t *testing.T) {
+	framer := logger.NewLineFramer(4, nil, nil)
+
+	assert.Equal(t, "abcd\nefgh\n", string(framer.Frame([]byte("abcdefghij"))), "Splits runaway input")
+	assert.Equal(t, "ij\n", string(framer.Frame([]byte("\n"))), "Completes the remainder of a split line")
+	assert.Equal(t, "abcd\n", string(framer.Frame([]byte("abcd\n"))), "Keeps lines of the maximum length")
+
+	framer = logger.NewLineFramer(0, nil, nil)
+	assert.Equal(t, "abcdefghij\n", string(framer.Frame([]byte("abcdefghij\n"))), "Zero disables the limit")
+}
+
+func TestLineFramerStamp(t *testing.T) {
+	var arrivals int
+
+	framer := logger.NewLineFramer(0, func(dst []byte) []byte {
+		arrivals++
+		return fmt.Appendf(dst, "%d ", arrivals)
+	}, nil)
+
+	assert.Empty(t, framer.Frame([]byte("Hello")), "Holds a partial line")
+	assert.Equal(t, "1 Hello world\n2 foo\n", string(framer.Frame([]byte(" world\nfoo\nbar"))), "Prefixes lines with the arrival of their first byte")
+	assert.Equal(t, "2 bar\n", string(framer.Rest()), "Prefixes the partial line")
+}
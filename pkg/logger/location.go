@@ -0,0 +1,37 @@
+package logger
+
+import "time"
+
+// Location extends time.Location with the pflag.Value interface. The zero value is UTC
+type Location struct {
+	*time.Location
+}
+
+// Set value from an IANA time zone name, UTC, or Local
+func (location *Location) Set(value string) (err error) {
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return
+	}
+
+	location.Location = loc
+	return
+}
+
+// Get returns the configured time.Location, defaulting to UTC
+func (location Location) Get() *time.Location {
+	if location.Location == nil {
+		return time.UTC
+	}
+
+	return location.Location
+}
+
+func (location Location) String() string {
+	return location.Get().String()
+}
+
+// Type description for CLI usage
+func (Location) Type() string {
+	return "timezone"
+}
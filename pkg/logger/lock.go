@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrLocked indicates that another process holds the lock on an output file
+var ErrLocked = errors.New("log file is locked by another process")
+
+// LockMode selects the behavior of a logger when another process holds the lock on its output file
+type LockMode string
+
+// Supported LockMode values
+const (
+	// LockFail returns ErrLocked immediately
+	LockFail LockMode = "fail"
+
+	// LockWait retries until the lock is released
+	LockWait LockMode = "wait"
+
+	// LockDelegate sends input and commands to the lock holder through its control socket
+	LockDelegate LockMode = "delegate"
+)
+
+// Set value from a string argument
+func (mode *LockMode) Set(value string) error {
+	switch LockMode(value) {
+	case LockFail, LockWait, LockDelegate:
+		*mode = LockMode(value)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported lock mode %q", value)
+}
+
+func (mode LockMode) String() string {
+	return string(mode)
+}
+
+// Type description for CLI usage
+func (LockMode) Type() string {
+	return "fail|wait|delegate"
+}
+
+// LockPath returns the path of the lock file for an output file
+func (opts RotatorOptions) LockPath(name string) string {
+	if opts.Directory {
+		return filepath.Join(filepath.Dir(name), LockFile)
+	}
+
+	return name + ".lock"
+}
+
+// lockFile acquires an exclusive advisory lock on the given path, recording the holder's PID in the file
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		pid, _ := os.ReadFile(path)
+		file.Close()
+
+		return nil, fmt.Errorf("%w: %s is held by process %s", ErrLocked, path, bytes.TrimSpace(pid))
+	}
+
+	if err == nil {
+		err = file.Truncate(0)
+	}
+
+	if err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// open creates a Rotator, retrying while the output file is locked if the LockWait mode is configured
+func open(ctx context.Context, name string, opts RotatorOptions) (*Rotator, error) {
+	for {
+		rotator, err := Open(name, opts)
+		if !errors.Is(err, ErrLocked) || opts.Lock != LockWait {
+			return rotator, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(time.Second):
+		}
+	}
+}
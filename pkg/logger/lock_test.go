@@ -0,0 +1,89 @@
+package logger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log")
+	opts := logger.RotatorOptions{Count: 2, CreateMode: 0o644, Lock: logger.LockFail}
+
+	rotator, err := logger.Open(name, opts)
+	assert.NoError(t, err, "Rotator created without error")
+
+	pid, err := os.ReadFile(opts.LockPath(name))
+	assert.NoError(t, err, "Test reads lock file")
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(pid), "Records PID of the lock holder")
+
+	_, err = logger.Open(name, opts)
+	assert.ErrorIs(t, err, logger.ErrLocked, "Second rotator fails to lock the output file")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Empty(t, versions, "Lock file is not a rotated version")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	rotator, err = logger.Open(name, opts)
+	assert.NoError(t, err, "Locks the output file after release")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestLockWait(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log")
+	opts := logger.RotatorOptions{Count: 2, CreateMode: 0o644, Lock: logger.LockWait}
+
+	holder, err := logger.Open(name, opts)
+	assert.NoError(t, err, "Rotator created without error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = logger.Rotate(ctx, name, opts, true)
+	assert.ErrorIs(t, err, logger.ErrLocked, "Stops waiting when the context is canceled")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		holder.Close()
+	}()
+
+	_, err = logger.Rotate(context.Background(), name, opts, true)
+	assert.NoError(t, err, "Waits for the lock to be released")
+}
+
+func TestLockDelegate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log")
+	opts := logger.RotatorOptions{Count: 2, CreateMode: 0o644, Lock: logger.LockDelegate, Control: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	reader, writer := NewCancelReaderPipe(ctx)
+	defer writer.Close()
+
+	go func() { done <- logger.Run(ctx, reader, name, opts, nil) }()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(opts.ControlPath(name))
+		return err == nil
+	}, time.Second, 10*time.Millisecond, "Creates control socket")
+
+	err := logger.Run(context.Background(), strings.NewReader("Hello world\n"), name, opts, nil)
+	assert.NoError(t, err, "Delegates input to the lock holder")
+
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, []byte("Hello world\n"), data)
+
+	cancel()
+	assert.NoError(t, <-done, "Lock holder returns without error")
+}
@@ -0,0 +1,326 @@
+package logger
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itchyny/timefmt-go"
+	"go.uber.org/multierr"
+)
+
+// Naming selects the scheme for rotated file names
+type Naming string
+
+// Supported Naming values
+const (
+	// NamingTimestamp suffixes rotated files with the time of rotation, formatted by Pattern
+	NamingTimestamp Naming = "timestamp"
+
+	// NamingNumeric renames the output file to NAME.1, shifting older versions to NAME.2, NAME.3 and so on
+	NamingNumeric Naming = "numeric"
+)
+
+// Set value from a string argument
+func (naming *Naming) Set(value string) error {
+	switch Naming(value) {
+	case NamingTimestamp, NamingNumeric:
+		*naming = Naming(value)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported naming scheme %q", value)
+}
+
+func (naming Naming) String() string {
+	return string(naming)
+}
+
+// Type description for CLI usage
+func (Naming) Type() string {
+	return "timestamp|numeric"
+}
+
+// versionName returns the name for the next rotated version of the output file
+func (rotator *Rotator) versionName() string {
+	if rotator.Directory {
+		// TAI64N labels only collide within a nanosecond: take a new label
+		name := directoryVersionName(rotator.versionPrefix(), ".s")
+		for rotator.versionExists(name) {
+			name = directoryVersionName(rotator.versionPrefix(), ".s")
+		}
+
+		return name
+	}
+
+	if rotator.Naming == NamingNumeric {
+		return rotator.versionPrefix() + ".1"
+	}
+
+	return rotator.availableName(rotator.versionPrefix() + "." + timefmt.Format(rotator.suffixTime(), rotator.Pattern))
+}
+
+// availableName returns name if no rotated version or compressed copy exists with it. Otherwise a counter suffix is
+// appended to name, e.g. name.1, following the highest counter in use so that later rotations sort after earlier ones
+func (rotator *Rotator) availableName(name string) string {
+	next := 0
+	if rotator.versionExists(name) {
+		next = 1
+	}
+
+	// Versions waiting to be moved to ArchiveDir hold their names at the staging path
+	prefixes := []string{name}
+	if rotator.ArchiveDir != "" {
+		prefixes = append(prefixes, rotator.stagingName(name))
+	}
+
+	for _, prefix := range prefixes {
+		entries, _ := os.ReadDir(filepath.Dir(prefix))
+		base := filepath.Base(prefix) + "."
+
+		for _, entry := range entries {
+			suffix, found := strings.CutPrefix(TrimCompression(entry.Name()), base)
+			if counter, ok := parseCounter(suffix); found && ok && counter >= next {
+				next = counter + 1
+			}
+		}
+	}
+
+	if next == 0 {
+		return name
+	}
+
+	return name + "." + strconv.Itoa(next)
+}
+
+// versionExists checks if a rotated version, a compressed copy of it, or a staged version waiting to be moved to
+// ArchiveDir exists
+func (rotator *Rotator) versionExists(name string) bool {
+	names := []string{name}
+	for _, compressor := range Compressors {
+		names = append(names, name+compressor.Extension())
+	}
+
+	if rotator.ArchiveDir != "" {
+		names = append(names, rotator.stagingName(name))
+	}
+
+	for _, name := range names {
+		if _, err := os.Lstat(name); !errors.Is(err, os.ErrNotExist) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseCounter parses a positive decimal number without a sign
+func parseCounter(value string) (int, bool) {
+	if value == "" || strings.Trim(value, "0123456789") != "" {
+		return 0, false
+	}
+
+	counter, err := strconv.Atoi(value)
+	return counter, err == nil && counter > 0
+}
+
+// isVersion checks if a file name is one given to rotated versions of the named output file by the naming scheme,
+// with an optional compression extension
+func (opts RotatorOptions) isVersion(name, version string) (ok bool) {
+	switch {
+	case opts.Directory:
+		ok = isDirectoryVersion(version)
+	case opts.Naming == NamingNumeric:
+		_, ok = opts.versionNumber(name, version)
+	default:
+		_, _, ok = opts.versionSuffix(name, version)
+	}
+
+	return
+}
+
+// versionSuffix parses the Pattern timestamp and collision counter from the suffix of a rotated version
+func (opts RotatorOptions) versionSuffix(name, version string) (timestamp time.Time, counter int, ok bool) {
+	suffix, found := strings.CutPrefix(TrimCompression(version), opts.VersionPrefix(name)+".")
+	if !found {
+		return
+	}
+
+	timestamp, err := timefmt.ParseInLocation(suffix, opts.Pattern, opts.Timezone.Get())
+	if err == nil {
+		return timestamp, 0, true
+	}
+
+	dot := strings.LastIndexByte(suffix, '.')
+	if dot < 0 {
+		return
+	}
+
+	if counter, ok = parseCounter(suffix[dot+1:]); !ok {
+		return
+	}
+
+	timestamp, err = timefmt.ParseInLocation(suffix[:dot], opts.Pattern, opts.Timezone.Get())
+	return timestamp, counter, err == nil
+}
+
+// VersionNumber parses the number of a rotated version named by NamingNumeric
+func (rotator *Rotator) VersionNumber(version string) (int, bool) {
+	return rotator.Options().versionNumber(rotator.Name(), version)
+}
+
+// versionNumber parses the number of a rotated version of the named output file
+func (opts RotatorOptions) versionNumber(name, version string) (int, bool) {
+	suffix, found := strings.CutPrefix(TrimCompression(version), opts.VersionPrefix(name)+".")
+	if !found {
+		return 0, false
+	}
+
+	return parseCounter(suffix)
+}
+
+// compareVersions returns a function that orders versions of the named output file from oldest to newest. Numbered
+// versions are ordered by descending number, after any other versions. Other versions are ordered lexically by their
+// uncompressed name, then by collision counter
+func (opts RotatorOptions) compareVersions(name string) func(a, b string) int {
+	return func(a, b string) int {
+		if opts.Naming == NamingNumeric && !opts.Directory {
+			an, aok := opts.versionNumber(name, a)
+			bn, bok := opts.versionNumber(name, b)
+
+			switch {
+			case aok && bok:
+				return cmp.Compare(bn, an)
+			case aok:
+				return 1
+			case bok:
+				return -1
+			}
+		}
+
+		a, ac := opts.splitCounter(name, a)
+		b, bc := opts.splitCounter(name, b)
+
+		if order := strings.Compare(a, b); order != 0 {
+			return order
+		}
+
+		return cmp.Compare(ac, bc)
+	}
+}
+
+// splitCounter removes any compression extension and collision counter from a version name
+func (opts RotatorOptions) splitCounter(name, version string) (string, int) {
+	base := TrimCompression(version)
+	if opts.Directory {
+		return base, 0
+	}
+
+	_, counter, ok := opts.versionSuffix(name, version)
+	if !ok || counter == 0 {
+		return base, 0
+	}
+
+	return base[:strings.LastIndexByte(base, '.')], counter
+}
+
+// queueName returns a hidden name beside the output file that it is rotated to under NamingNumeric, so that
+// numbered versions are shifted by archive instead of blocking input. TAI64N labels order queued files by rotation
+func (rotator *Rotator) queueName() string {
+	prefix := rotator.stagingName(rotator.Name()) + "."
+
+	name := prefix + string(AppendTAI64N(nil, time.Now()))
+	for _, err := os.Lstat(name); !errors.Is(err, os.ErrNotExist); _, err = os.Lstat(name) {
+		name = prefix + string(AppendTAI64N(nil, time.Now()))
+	}
+
+	return name
+}
+
+// dequeue numbers queued files from oldest to newest, up to and including last if it is set. Numbered versions are
+// shifted before each queued file is moved to the first number, then processed if process is set. Failures are
+// reported to Alerts and stop dequeue, so that the remaining files are numbered in order by the next call. The caller
+// must hold the maintenance lock, or have exclusive use of the Rotator
+func (rotator *Rotator) dequeue(last string, process bool) (err error) {
+	opts := rotator.Options()
+	prefix := rotator.stagingName(rotator.Name()) + "."
+
+	matches, err := filepath.Glob(prefix + "@*")
+	if err != nil {
+		return
+	}
+
+	// Glob sorts matches, and TAI64N labels sort by time
+	for _, queued := range matches {
+		if _, perr := ParseTAI64N(strings.TrimPrefix(queued, prefix)); perr != nil {
+			continue
+		}
+
+		if last != "" && queued > last {
+			break
+		}
+
+		version := opts.VersionPrefix(rotator.Name()) + ".1"
+
+		err = opts.shiftVersions(rotator.Name())
+		if err == nil {
+			err = moveFile(queued, version)
+		}
+
+		if err != nil {
+			if opts.Alerts != nil {
+				fmt.Fprintf(opts.Alerts, "unable to move %s to %s: %s\n", queued, version, err)
+			}
+
+			return
+		}
+
+		if process && opts.Processor != "" {
+			rotator.process(version)
+		}
+	}
+
+	return
+}
+
+// shiftVersions renames each numbered version of the named output file, including compressed copies, to the next
+// number. The caller must hold the maintenance lock
+func (opts RotatorOptions) shiftVersions(name string) (err error) {
+	prefix := opts.VersionPrefix(name)
+
+	matches, err := filepath.Glob(prefix + ".*")
+	if err != nil {
+		return
+	}
+
+	var numbered []string
+
+	for _, match := range matches {
+		if _, ok := opts.versionNumber(name, match); ok {
+			numbered = append(numbered, match)
+		}
+	}
+
+	// Rename the highest numbers first so that no version is overwritten
+	slices.SortFunc(numbered, func(a, b string) int {
+		an, _ := opts.versionNumber(name, a)
+		bn, _ := opts.versionNumber(name, b)
+
+		return cmp.Compare(bn, an)
+	})
+
+	for _, version := range numbered {
+		number, _ := opts.versionNumber(name, version)
+		extension := strings.TrimPrefix(version, TrimCompression(version))
+
+		err = multierr.Append(err, os.Rename(version, prefix+"."+strconv.Itoa(number+1)+extension))
+	}
+
+	return
+}
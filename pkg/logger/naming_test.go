@@ -0,0 +1,203 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatorNumeric(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		Enabled:       true,
+		MaxSize:       1024,
+		MaxAge:        time.Hour,
+		Count:         3,
+		Naming:        logger.NamingNumeric,
+		CreateMode:    0o644,
+		Compress:      "gzip",
+		CompressDelay: 1,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 5; i++ {
+		_, err = rotator.Write([]byte(fmt.Sprintf("line %d\n", i)))
+		assert.NoError(t, err, "Write without error")
+
+		rotated, err := rotator.Force()
+		assert.NoError(t, err, "Rotate without error")
+		assert.True(t, rotated)
+	}
+
+	// Close waits for background compression and cleanup
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, []string{name + ".3.gz", name + ".2.gz", name + ".1"}, versions, "Orders numbered versions from oldest to newest")
+
+	data, err := os.ReadFile(name + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, "line 4\n", string(data), "The newest version is numbered 1")
+
+	reader, err := os.Open(name + ".3.gz")
+	assert.NoError(t, err)
+
+	defer reader.Close()
+
+	decompressor, err := logger.Gzip{}.NewReader(reader)
+	assert.NoError(t, err)
+
+	data = make([]byte, 64)
+	n, _ := decompressor.Read(data)
+	assert.Equal(t, "line 2\n", string(data[:n]), "Shifts compressed versions")
+}
+
+func TestRotatorNumericProcessor(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      3,
+		Naming:     logger.NamingNumeric,
+		CreateMode: 0o644,
+		Processor:  "sleep 1; tr a-z A-Z",
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	archived := make(chan struct{}, 3)
+	rotator.OnRotate(func(_ context.Context, event logger.RotateEvent) {
+		if event.Stage == logger.AfterArchive {
+			archived <- struct{}{}
+		}
+	})
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err = rotator.Write([]byte(fmt.Sprintf("line %d\n", i)))
+		assert.NoError(t, err, "Write without error")
+
+		rotated, err := rotator.Force()
+		assert.NoError(t, err, "Rotate without error")
+		assert.True(t, rotated)
+	}
+
+	assert.Less(t, time.Since(start), time.Second, "Rotation does not wait for the Processor")
+
+	for i := 0; i < 3; i++ {
+		<-archived
+	}
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, []string{name + ".3", name + ".2", name + ".1"}, versions, "Numbers queued versions in order")
+
+	for i, version := range versions {
+		data, err := os.ReadFile(version)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("LINE %d\n", i), string(data), "Processes each version once")
+	}
+}
+
+func TestRotatorNumericOrder(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+
+	for _, suffix := range []string{"1", "2", "9", "10", "10.gz.tmp"} {
+		os.WriteFile(name+"."+suffix, nil, 0o644)
+	}
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{Naming: logger.NamingNumeric, CreateMode: 0o644})
+	assert.NoError(t, err, "Rotator created without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, []string{name + ".10", name + ".9", name + ".2", name + ".1"}, versions, "Sorts numerically, without unnumbered files")
+
+	number, ok := rotator.VersionNumber(name + ".10.gz")
+	assert.True(t, ok)
+	assert.Equal(t, 10, number, "Parses numbers of compressed versions")
+
+	_, ok = rotator.VersionNumber(name + ".-1")
+	assert.False(t, ok, "Rejects invalid numbers")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorVersionDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "service.log")
+
+	versions := []string{
+		name + ".2024-01-31",
+		name + ".2024-01-31.1.gz",
+		name + ".2024-01-31.2",
+		name + ".2024-02-01.zst",
+	}
+
+	unrelated := []string{
+		name + ".swp",
+		name + ".old",
+		name + ".1",
+		name + ".2024-01-31.tmp",
+		name + ".2024-01-31.gz.tmp",
+		filepath.Join(dir, "other.log.2024-01-31"),
+	}
+
+	for _, file := range slices.Concat(versions, unrelated) {
+		assert.NoError(t, os.WriteFile(file, []byte("Hello world\n"), 0o644), "Test writes file")
+	}
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{Count: 1, Pattern: "%Y-%m-%d", CreateMode: 0o644})
+	assert.NoError(t, err, "Rotator created without error")
+
+	found, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, versions, found, "Only matches names given by Pattern, with counters and compression extensions")
+
+	assert.NoError(t, rotator.Cleanup(), "Cleanup without error")
+
+	found, err = rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, versions[3:], found, "Removes the oldest versions")
+
+	for _, file := range unrelated {
+		assert.FileExists(t, file, "Cleanup does not remove unrelated files")
+	}
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	// Versions are listed without a running logger
+	found, err = logger.Versions(context.Background(), name, logger.RotatorOptions{Pattern: "%Y-%m-%d", CreateMode: 0o644})
+	assert.NoError(t, err, "Lists versions without error")
+	assert.Equal(t, versions[3:], found)
+
+	found, err = logger.Versions(context.Background(), name, logger.RotatorOptions{Naming: logger.NamingNumeric, CreateMode: 0o644})
+	assert.NoError(t, err, "Lists versions without error")
+	assert.Equal(t, []string{name + ".1"}, found, "Numeric naming only matches numbered versions")
+}
+
+func TestNamingFlag(t *testing.T) {
+	var naming logger.Naming
+
+	assert.NoError(t, naming.Set("numeric"))
+	assert.Equal(t, logger.NamingNumeric, naming)
+	assert.Error(t, naming.Set("sequential"), "Rejects unknown schemes")
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// ProcessFile runs a shell command in the file's directory with the named file as its stdin. If the command exits
+// successfully, the file is replaced with the command's stdout. Otherwise the file is kept unchanged
+func ProcessFile(ctx context.Context, name, command string, stderr io.Writer) error {
+	return transformFile(name, name, func(dst, src *os.File) error {
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+		cmd.Dir = filepath.Dir(name)
+		cmd.Stdin = src
+		cmd.Stdout = dst
+		cmd.Stderr = stderr
+
+		// Don't wait on descendants of the shell that hold its output open after a timeout
+		cmd.WaitDelay = time.Second
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("processor %q failed for %s: %w", command, name, err)
+		}
+
+		return nil
+	})
+}
+
+// process runs the Processor on a rotated version, reporting failures to Alerts. The Processor is stopped if the
+// Rotator is closed
+func (rotator *Rotator) process(version string) {
+	ctx := rotator.ctx
+	opts := rotator.Options()
+
+	if opts.ProcessorTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, opts.ProcessorTimeout)
+		defer cancel()
+	}
+
+	err := ProcessFile(ctx, version, opts.Processor, opts.Alerts)
+	if err != nil && opts.Alerts != nil {
+		fmt.Fprintln(opts.Alerts, err)
+	}
+}
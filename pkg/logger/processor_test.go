@@ -0,0 +1,51 @@
+package logger_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log.1")
+	os.WriteFile(name, []byte("Hello world\n"), 0o640)
+
+	assert.NoError(t, logger.ProcessFile(context.Background(), name, "tr a-z A-Z", nil), "Processes file without error")
+
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err, "Test reads back processed file")
+	assert.Equal(t, "HELLO WORLD\n", string(data), "Replaces file with processor output")
+
+	stat, err := os.Stat(name)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), stat.Mode().Perm(), "Keeps the file's mode")
+
+	entries, err := os.ReadDir(filepath.Dir(name))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "Removes temporary files")
+}
+
+func TestProcessFileFailure(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log.1")
+	os.WriteFile(name, []byte("Hello world\n"), 0o644)
+
+	assert.Error(t, logger.ProcessFile(context.Background(), name, "echo partial; exit 1", nil), "Returns processor failures")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, logger.ProcessFile(ctx, name, "sleep 5", nil), "Returns processor timeouts")
+
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err, "Test reads back file")
+	assert.Equal(t, "Hello world\n", string(data), "Keeps the original file after failures")
+
+	entries, err := os.ReadDir(filepath.Dir(name))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "Removes temporary files")
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+)
+
+// DefaultRemotePort is used for Remote addresses without a port, following syslog
+const DefaultRemotePort = "514"
+
+// dialRemote connects to a UDP address for the Remote option
+func dialRemote(address string) (net.Conn, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultRemotePort)
+	}
+
+	return net.Dial("udp", address)
+}
+
+// send transmits a prefixed line to the Remote address. Errors are reported to Alerts
+func (rotator *Rotator) send(line []byte) {
+	message := append([]byte(rotator.Prefix), line...)
+
+	if _, err := rotator.remote.Write(message); err != nil && rotator.Alerts != nil {
+		fmt.Fprintf(rotator.Alerts, "unable to send line to %s: %s\n", rotator.Remote, err)
+	}
+}
@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeRemote(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err, "Listens for UDP packets")
+
+	defer listener.Close()
+
+	name := filepath.Join(t.TempDir(), "log")
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		CreateMode: 0o644,
+		Select:     logger.Selectors{{Action: '-', Pattern: "*health*"}},
+		Remote:     listener.LocalAddr().String(),
+		RemoteOnly: true,
+		Prefix:     "web: ",
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	err = rotator.Pipe(context.Background(), strings.NewReader("GET /health\nGET /index\n"))
+	assert.NoError(t, err, "Pipe returns without error at EOF")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	buffer := make([]byte, 1024)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+
+	n, _, err := listener.ReadFrom(buffer)
+	assert.NoError(t, err, "Receives a selected line")
+	assert.Equal(t, "web: GET /index", string(buffer[:n]), "Sends prefixed lines")
+
+	content, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Empty(t, content, "Does not write lines to the output file with RemoteOnly")
+}
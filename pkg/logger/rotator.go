@@ -1,16 +1,20 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/itchyny/timefmt-go"
 	"go.uber.org/multierr"
 	"storj.io/common/memory"
 )
@@ -24,32 +28,150 @@ type RotatorOptions struct {
 	MaxAge  time.Duration
 	Count   int
 
+	MinCount int
+
+	MaxTotalSize memory.Size
+	RetainFor    Duration
+	MinFree      memory.Size
+
+	CheckInterval time.Duration
+	RotateAt      Schedule
+
 	Pattern    string
+	Naming     Naming
+	ArchiveDir string // Directory for rotated versions, if not beside the output file
+	Directory  bool   // Set by Open when the log path is an svlogd log directory
+	Timezone   Location
 	CreateMode FileMode
+
+	Compress      string
+	CompressLevel int
+	CompressDelay int
+
+	Processor        string
+	ProcessorTimeout time.Duration
+
+	Control       bool
+	ControlSocket string
+
+	Lock LockMode
+
+	OnError         ErrorPolicy
+	ErrorBufferSize memory.Size
+
+	MaxLineLength memory.Size
+	Timestamp     Timestamp
+
+	// Select lines for the output file, and for alerts written to Alerts
+	Select Selectors
+	Alerts io.Writer
+
+	// Send selected lines to a UDP address, in addition to or instead of the output file
+	Remote     string
+	RemoteOnly bool
+	Prefix     string
 }
 
-// Run pipes log lines from a reader to a file at the given path.
-func Run(ctx context.Context, src io.Reader, path string, opts RotatorOptions) (err error) {
-	rotator, err := Open(path, opts)
+// Run pipes log lines from a reader to a file at the given path. Triggers received while running are applied to the Rotator
+func Run(ctx context.Context, src io.Reader, path string, opts RotatorOptions, triggers <-chan Trigger) (err error) {
+	rotator, err := open(ctx, path, opts)
+	if errors.Is(err, ErrLocked) && opts.Lock == LockDelegate {
+		// Send input to the process that holds the lock on the output file
+		return Forward(ctx, opts.ControlPath(path), src)
+	}
+
 	if err != nil {
 		return
 	}
 
 	defer rotator.Close()
+
+	// A failed trigger cancels the pipe with its error
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if opts.Control {
+		listener, err := Listen(opts.ControlPath(path))
+		if err != nil {
+			return err
+		}
+
+		rotator.wait.Add(1)
+		go func() {
+			defer rotator.wait.Done()
+			rotator.Serve(ctx, listener)
+		}()
+	}
+
+	rotator.wait.Add(1)
+	go func() {
+		defer rotator.wait.Done()
+		cancel(rotator.Handle(ctx, triggers))
+	}()
+
 	return rotator.Pipe(ctx, src)
 }
 
-// Rotate performs a one-shot rotation on the given log path
-func Rotate(_ context.Context, path string, opts RotatorOptions) (_ bool, err error) {
-	rotator, err := Open(path, opts)
+// Rotate performs a one-shot rotation on the given log path. If force is set, the output file is rotated regardless of its size and age.
+// Rotation is delegated to a running logger through its control socket if one exists
+func Rotate(ctx context.Context, path string, opts RotatorOptions, force bool) (_ bool, err error) {
+	command := []string{"rotate"}
+	if force {
+		command = append(command, "force")
+	}
+
+	if opts.Control {
+		response, err := Control(ctx, opts.ControlPath(path), command...)
+		if !errors.Is(err, ErrNotRunning) {
+			return response == "rotated true\n", err
+		}
+	}
+
+	rotator, err := open(ctx, path, opts)
 	if err != nil {
 		return
 	}
 
 	defer rotator.Close()
+
+	if force {
+		return rotator.Force()
+	}
+
 	return rotator.Rotate()
 }
 
+// Versions lists the rotated versions of the output file at the given log path, oldest first. The list is requested
+// from a running logger through its control socket if one exists. The log path is not locked or modified
+func Versions(ctx context.Context, path string, opts RotatorOptions) (_ []string, err error) {
+	if opts.Control {
+		response, err := Control(ctx, opts.ControlPath(path), "versions")
+		if !errors.Is(err, ErrNotRunning) {
+			// One version per line, each terminated by a newline
+			versions := strings.Split(response, "\n")
+			return versions[:len(versions)-1], err
+		}
+	}
+
+	// List versions without locking or opening the output file, so that listing never changes the log path
+	name, directory := ResolvePath(path)
+	if directory {
+		opts.Directory = true
+	}
+
+	return opts.listVersions(name)
+}
+
+// ControlPath returns the configured control socket path, or a default path derived from the output file's path
+func (opts RotatorOptions) ControlPath(name string) string {
+	if opts.ControlSocket != "" {
+		return opts.ControlSocket
+	}
+
+	name, _ = ResolvePath(name)
+	return name + ".sock"
+}
+
 // WriteRotator extends io.WriteRotator with methods to aide in log-file rotation
 type WriteRotator interface {
 	io.WriteCloser
@@ -57,7 +179,9 @@ type WriteRotator interface {
 
 	Open(string, fs.FileMode) error
 	Reopen(string, fs.FileMode) error
+	Refresh(fs.FileMode) error
 	Truncate() error
+	Sync() error
 
 	Age() time.Duration
 	Name() string
@@ -69,42 +193,203 @@ type WriteRotator interface {
 type Rotator struct {
 	RotatorOptions
 	WriteRotator
+
+	// Guard RotatorOptions against Reconfigure for readers that do not hold the rotating or backlog locks
+	settings sync.RWMutex
+
+	// Serialize rotation of the output file between writes and triggers
+	rotating sync.Mutex
+
+	// Advisory lock on the output file, held until Close
+	lock *os.File
+
+	// Connection to the Remote address
+	remote net.Conn
+
+	// Start of the output file's rotation period, and the next RotateAt boundary after it
+	period   time.Time
+	boundary time.Time
+
+	// Serialize background compression and cleanup of rotated files
+	maintenance sync.Mutex
+	wait        sync.WaitGroup
+
+	// Input held after write errors, and error counters
+	backlog backlog
+
+	// OnRotate hooks, and their context that is canceled by Close
+	hooks  []func(context.Context, RotateEvent)
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // Open configures a new Rotator and loads the current state of the output file
 func Open(name string, opts RotatorOptions) (_ *Rotator, err error) {
+	if err = opts.checkCompress(); err != nil {
+		return
+	}
+
+	if err = opts.checkArchiveDir(); err != nil {
+		return
+	}
+
+	if resolved, directory := ResolvePath(name); directory {
+		name = resolved
+		opts.Directory = true
+	}
+
 	rotator := &Rotator{RotatorOptions: opts}
 
+	rotator.lock, err = lockFile(opts.LockPath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Directory {
+		if _, err = salvage(name, opts.VersionPrefix(name)); err != nil {
+			rotator.lock.Close()
+			return nil, err
+		}
+	}
+
+	if opts.Remote != "" {
+		rotator.remote, err = dialRemote(opts.Remote)
+		if err != nil {
+			rotator.lock.Close()
+			return nil, err
+		}
+	}
+
 	rotator.WriteRotator, err = OpenFileWriter(name, rotator.Mode())
 	if err != nil {
+		rotator.lock.Close()
+		rotator.closeRemote()
 		return nil, err
 	}
 
+	if opts.Directory {
+		// Mark the output file as unfinished until it is closed
+		os.Chmod(name, rotator.Mode())
+	}
+
+	// Retry moves of versions left beside the output file. Failures are reported by unstage and dequeue
+	rotator.unstage()
+
+	if opts.Naming == NamingNumeric && !opts.Directory {
+		rotator.dequeue("", false)
+	}
+
+	rotator.ctx, rotator.cancel = context.WithCancel(context.Background())
+	rotator.begin(rotator.Created())
+
 	return rotator, nil
 }
 
+// Options returns a snapshot of the Rotator's current options
+func (rotator *Rotator) Options() RotatorOptions {
+	rotator.settings.RLock()
+	defer rotator.settings.RUnlock()
+
+	return rotator.RotatorOptions
+}
+
+// Reconfigure replaces the Rotator's options without interrupting input. The Control, ControlSocket, Lock, Directory
+// and ArchiveDir options of a running Rotator are not changed, and MaxLineLength only applies to new input sources
+func (rotator *Rotator) Reconfigure(opts RotatorOptions) (err error) {
+	if err = opts.checkCompress(); err != nil {
+		return
+	}
+
+	// Acquire locks in the same order as writes and rotation. Maintenance reads a snapshot of the options, so that
+	// Reconfigure does not wait for processing or compression while holding input
+	rotator.backlog.Lock()
+	defer rotator.backlog.Unlock()
+
+	rotator.rotating.Lock()
+	defer rotator.rotating.Unlock()
+
+	rotator.settings.Lock()
+	defer rotator.settings.Unlock()
+
+	if opts.Remote != rotator.Remote {
+		var remote net.Conn
+
+		if opts.Remote != "" {
+			remote, err = dialRemote(opts.Remote)
+			if err != nil {
+				return
+			}
+		}
+
+		rotator.closeRemote()
+		rotator.remote = remote
+	}
+
+	opts.Control = rotator.Control
+	opts.ControlSocket = rotator.ControlSocket
+	opts.Lock = rotator.RotatorOptions.Lock
+	opts.Directory = rotator.Directory
+	opts.ArchiveDir = rotator.ArchiveDir
+
+	rotator.RotatorOptions = opts
+
+	// Apply a new schedule or timezone to the current rotation period
+	rotator.begin(rotator.Created())
+
+	return
+}
+
 // Mode returns the writer's configured CreateMode
 func (rotator *Rotator) Mode() fs.FileMode {
 	return fs.FileMode(rotator.CreateMode)
 }
 
-// Versions lists rotated files in the same directory as the current output file
-func (rotator *Rotator) Versions() (versions []string, err error) {
-	versions, err = filepath.Glob(rotator.Name() + ".*")
+// Versions lists rotated files of the output file, in its directory or ArchiveDir, with names given by its naming scheme
+func (rotator *Rotator) Versions() ([]string, error) {
+	return rotator.Options().listVersions(rotator.Name())
+}
+
+// listVersions lists rotated files of the named output file, oldest first, without opening it
+func (opts RotatorOptions) listVersions(name string) (versions []string, err error) {
+	matches, err := filepath.Glob(opts.versionGlob(name))
 	if err != nil {
 		return
 	}
 
-	// Sort by timestamp-suffix
-	slices.Sort(versions)
+	for _, match := range matches {
+		// Skip sockets, directories and other special files
+		if stat, err := os.Lstat(match); err != nil || !stat.Mode().IsRegular() {
+			continue
+		}
+
+		// Only accept names given to rotated versions, so that unrelated files are never removed by Cleanup
+		if !opts.isVersion(name, match) {
+			continue
+		}
+
+		// A compressed copy is only a duplicate of its source until the source is removed
+		if IsCompressed(match) && slices.Contains(matches, TrimCompression(match)) {
+			continue
+		}
+
+		versions = append(versions, match)
+	}
+
+	// Sort oldest first, by timestamp or number suffix
+	slices.SortFunc(versions, opts.compareVersions(name))
 
 	return
 }
 
 // NeedsRotation checks if the output file needs to be rotated
 func (rotator *Rotator) NeedsRotation() bool {
+	return rotator.reason() != ""
+}
+
+// reason returns the reason that the output file needs to be rotated, or an empty string if it does not
+func (rotator *Rotator) reason() RotateReason {
 	if !rotator.Enabled {
-		return false
+		return ""
 	}
 
 	// Get a single value for the current output file size. Access is not synchronized
@@ -112,75 +397,542 @@ func (rotator *Rotator) NeedsRotation() bool {
 
 	// Rotate on output file size
 	if size >= int64(rotator.MaxSize) {
-		return true
+		return ReasonSize
+	}
+
+	if rotator.RotateAt.Enabled() {
+		// Rotate on calendar boundaries instead of the rolling MaxAge
+		if rotator.scheduled(size) {
+			return ReasonSchedule
+		}
+
+		return ""
+	}
+
+	// Rotate on output file age if size meets the minimum threshold. Empty files are never rotated by age
+	if rotator.Age() > rotator.MaxAge && size >= int64(rotator.MinSize) && size > 0 {
+		return ReasonAge
+	}
+
+	return ""
+}
+
+// scheduled checks if the output file has passed the next boundary of the RotateAt schedule
+func (rotator *Rotator) scheduled(size int64) bool {
+	now := rotator.now()
+	if now.Before(rotator.boundary) {
+		return false
 	}
 
-	// Rotate on output file age if size meets the minimum threshold
-	if rotator.Age() > rotator.MaxAge && size >= int64(rotator.MinSize) {
+	if size > 0 {
 		return true
 	}
 
+	// Empty files are not rotated: the next line written begins a new period
+	rotator.begin(now)
 	return false
 }
 
+// begin a new rotation period for the output file
+func (rotator *Rotator) begin(start time.Time) {
+	rotator.period = start.In(rotator.Timezone.Get())
+
+	if rotator.RotateAt.Enabled() {
+		rotator.boundary = rotator.RotateAt.Next(rotator.period)
+	}
+}
+
+// now returns the current time in the configured Timezone for naming and scheduling rotations
+func (rotator *Rotator) now() time.Time {
+	return time.Now().In(rotator.Timezone.Get())
+}
+
 // Rotate closes, renames, then reopens the output file if it requires rotation according to the Writer's configuration
 func (rotator *Rotator) Rotate() (rotated bool, err error) {
-	if !rotator.NeedsRotation() {
+	return rotator.rotateIf(rotator.reason)
+}
+
+// Force rotates the output file regardless of the Rotator's size and age thresholds. Empty output files are not rotated
+func (rotator *Rotator) Force() (rotated bool, err error) {
+	return rotator.rotateIf(func() RotateReason {
+		if rotator.Size() == 0 {
+			return ""
+		}
+
+		return ReasonForced
+	})
+}
+
+// rotateIf rotates the output file if check returns a reason for rotation
+func (rotator *Rotator) rotateIf(check func() RotateReason) (rotated bool, err error) {
+	rotator.rotating.Lock()
+	defer rotator.rotating.Unlock()
+
+	reason := check()
+	if reason == "" {
+		return
+	}
+
+	event, queued, err := rotator.rotate(reason)
+	if err != nil {
+		return
+	}
+
+	// Run version processing, compression and cleanup asynchronously
+	rotator.wait.Add(1)
+	go rotator.archive(event, queued)
+
+	return true, nil
+}
+
+// Refresh closes and reopens the output file at its configured path
+func (rotator *Rotator) Refresh() (err error) {
+	rotator.rotating.Lock()
+	defer rotator.rotating.Unlock()
+
+	err = rotator.WriteRotator.Refresh(rotator.Mode())
+	if err != nil {
 		return
 	}
 
-	if rotator.Count == 0 {
+	rotator.begin(rotator.Created())
+	return
+}
+
+// rotate renames the output file and reopens it. Under NamingNumeric, the output file is renamed to the returned
+// queue name, to be numbered by archive
+func (rotator *Rotator) rotate(reason RotateReason) (event RotateEvent, queued string, err error) {
+	event = RotateEvent{
+		Stage:   BeforeRotate,
+		Reason:  reason,
+		OldName: rotator.Name(),
+		Size:    rotator.Size(),
+		Age:     rotator.Age(),
+	}
+
+	if rotator.Count != 0 {
+		event.NewName = rotator.versionName()
+	}
+
+	rotator.emit(event)
+
+	// Versions for ArchiveDir are rotated beside the output file, then moved by archive
+	target := event.NewName
+	if rotator.ArchiveDir != "" && target != "" {
+		target = rotator.stagingName(target)
+	}
+
+	if event.NewName == "" {
 		// Special case: Truncate the output file in place
 		err = rotator.Truncate()
+	} else if rotator.Directory {
+		err = rotator.Reopen(target, rotator.Mode())
+		if err == nil {
+			// Mark the rotated file as finished, following svlogd. Failure does not affect the rotated contents
+			os.Chmod(target, rotator.Mode()|finished)
+		}
+	} else if rotator.Naming == NamingNumeric {
+		// Queue the current file to be renamed to the first number once archive has shifted numbered versions
+		queued = rotator.queueName()
+
+		err = rotator.Reopen(queued, rotator.Mode())
+		if err != nil {
+			queued = ""
+		}
 	} else {
 		// Rename the current file with a timestamp suffix then create a new empty output file
-		err = rotator.Reopen(rotator.Name()+"."+timefmt.Format(time.Now().UTC(), rotator.Pattern), rotator.Mode())
+		err = rotator.Reopen(target, rotator.Mode())
 	}
 
+	event.Stage = AfterRotate
+	event.Err = err
+	rotator.emit(event)
+
 	if err != nil {
 		return
 	}
 
-	// Run version cleanup asynchronously
-	go rotator.Cleanup()
-	return true, nil
+	rotator.begin(rotator.now())
+
+	return
 }
 
-// Cleanup attempts to remove outdated rotated files
-func (rotator *Rotator) Cleanup() (err error) {
-	// Count == -1 disables cleanup
-	if rotator.Count < 0 {
+// archive runs the Processor on a newly rotated version and compresses versions if configured, then removes
+// outdated versions. A queued file is numbered, along with any that were queued before it, then processed
+func (rotator *Rotator) archive(event RotateEvent, queued string) {
+	defer rotator.wait.Done()
+
+	rotator.maintenance.Lock()
+	defer rotator.maintenance.Unlock()
+
+	// Maintenance does not block Reconfigure, so options are read from a snapshot
+	opts := rotator.Options()
+
+	// Move the new version to ArchiveDir or its number. Failures are reported to Alerts instead of stopping input
+	var unstaged error
+
+	if queued != "" {
+		unstaged = rotator.dequeue(queued, true)
+	} else {
+		unstaged = rotator.unstage()
+
+		if opts.Processor != "" && event.NewName != "" {
+			rotator.process(event.NewName)
+		}
+	}
+
+	// Compress first so that retention by size counts compressed versions. Failures are reported to Alerts, and
+	// versions that were not compressed are retried after the next rotation
+	var compressed error
+	if compressor, ok := Compressors[opts.Compress]; ok {
+		compressed = rotator.CompressVersions(compressor)
+
+		if compressed != nil && opts.Alerts != nil {
+			fmt.Fprintln(opts.Alerts, "unable to compress rotated files:", compressed)
+		}
+	}
+
+	event.Stage = AfterArchive
+	event.Err = multierr.Combine(unstaged, compressed, rotator.Cleanup())
+
+	if event.NewName != "" {
+		event.NewName = archived(event.NewName)
+	}
+
+	rotator.emit(event)
+}
+
+// CompressVersions compresses rotated files, leaving the newest CompressDelay versions uncompressed
+func (rotator *Rotator) CompressVersions(compressor Compressor) (err error) {
+	opts := rotator.Options()
+
+	versions, err := opts.listVersions(rotator.Name())
+	if err != nil {
 		return
 	}
 
+	if compress := len(versions) - opts.CompressDelay; compress > 0 {
+		// Compress oldest (first in sorted slice) rotated files
+		versions = versions[:compress]
+
+		for _, version := range versions {
+			if !IsCompressed(version) {
+				err = multierr.Append(err, CompressFile(version, compressor, opts.CompressLevel))
+			}
+		}
+	}
+
+	return
+}
+
+// suffixTime returns the time used to name a rotated file. Files rotated on a RotateAt boundary are named
+// by the start of the period that they contain
+func (rotator *Rotator) suffixTime() time.Time {
+	now := rotator.now()
+
+	if rotator.RotateAt.Enabled() && !now.Before(rotator.boundary) {
+		return rotator.period
+	}
+
+	return now
+}
+
+// Cleanup attempts to remove outdated rotated files
+func (rotator *Rotator) Cleanup() (err error) {
+	opts := rotator.Options()
+
 	// Find timestamp-suffixed output file versions
-	versions, err := rotator.Versions()
+	versions, err := opts.listVersions(rotator.Name())
 	if err != nil {
 		return
 	}
 
-	if remove := len(versions) - rotator.Count; remove > 0 {
+	// Count == -1 disables retention by count
+	if remove := len(versions) - opts.Count; opts.Count >= 0 && remove > 0 {
 		// Remove oldest (first in sorted slice) rotated files, retaining newest $Count files
-		versions = versions[:remove]
-
-		for _, version := range versions {
+		for _, version := range versions[:remove] {
 			// Try to remove all outdated versions
-			err = multierr.Append(err, os.Remove(version))
+			err = multierr.Append(err, removeVersion(version))
 		}
+
+		versions = versions[remove:]
+	}
+
+	if opts.RetainFor > 0 {
+		var rerr error
+
+		versions, rerr = opts.cleanupAge(rotator.Name(), versions)
+		err = multierr.Append(err, rerr)
+	}
+
+	if opts.MaxTotalSize > 0 {
+		err = multierr.Append(err, rotator.cleanupSize(versions, opts.MaxTotalSize))
 	}
 
 	return
 }
 
-// Pipe reads from a source io.Reader to the Writer's rotated output file
-func (rotator *Rotator) Pipe(ctx context.Context, src io.Reader) (err error) {
-	_, err = io.Copy(rotator, NewCancelReader(ctx, src))
+// cleanupAge removes versions of the named output file older than RetainFor, returning the remaining versions
+func (opts RotatorOptions) cleanupAge(name string, versions []string) (remaining []string, err error) {
+	cutoff := time.Now().Add(-time.Duration(opts.RetainFor))
+
+	for _, version := range versions {
+		timestamp, terr := opts.versionTime(name, version)
+		if terr != nil {
+			err = multierr.Append(err, terr)
+			remaining = append(remaining, version)
+			continue
+		}
+
+		if timestamp.Before(cutoff) {
+			err = multierr.Append(err, removeVersion(version))
+			continue
+		}
+
+		remaining = append(remaining, version)
+	}
+
+	return
+}
+
+// VersionTime parses the timestamp of a rotated file from its Pattern suffix, falling back to its mtime
+func (rotator *Rotator) VersionTime(version string) (time.Time, error) {
+	return rotator.Options().versionTime(rotator.Name(), version)
+}
+
+// versionTime parses the timestamp of a rotated version of the named output file
+func (opts RotatorOptions) versionTime(name, version string) (time.Time, error) {
+	if opts.Directory {
+		if timestamp, err := directoryVersionTime(version); err == nil {
+			return timestamp, nil
+		}
+	} else if timestamp, _, ok := opts.versionSuffix(name, version); ok {
+		return timestamp, nil
+	}
+
+	stat, err := os.Stat(version)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return stat.ModTime(), nil
+}
+
+// cleanupSize removes the oldest versions until the output file and remaining versions fit within limit
+func (rotator *Rotator) cleanupSize(versions []string, limit memory.Size) (err error) {
+	total := rotator.Size()
+	sizes := make([]int64, len(versions))
+
+	for i, version := range versions {
+		stat, serr := os.Stat(version)
+		if serr != nil {
+			err = multierr.Append(err, serr)
+			continue
+		}
+
+		sizes[i] = stat.Size()
+		total += sizes[i]
+	}
+
+	for i, version := range versions {
+		if total <= int64(limit) {
+			break
+		}
+
+		err = multierr.Append(err, removeVersion(version))
+		total -= sizes[i]
+	}
+
+	return
+}
+
+// removeVersion deletes a rotated file along with any compressed copies left by an interrupted compression
+func removeVersion(version string) (err error) {
+	err = os.Remove(version)
+
+	if IsCompressed(version) {
+		return
+	}
+
+	for _, compressor := range Compressors {
+		if cerr := os.Remove(version + compressor.Extension()); !errors.Is(cerr, os.ErrNotExist) {
+			err = multierr.Append(err, cerr)
+		}
+	}
+
+	return
+}
+
+// Close waits for background routines to complete, then closes the output file and releases its lock. The context
+// passed to OnRotate hooks is canceled before waiting
+func (rotator *Rotator) Close() (err error) {
+	rotator.cancel()
+	rotator.wait.Wait()
+
+	rotator.rotating.Lock()
+	defer rotator.rotating.Unlock()
+
+	err = multierr.Append(err, rotator.WriteRotator.Close())
+
+	if rotator.Directory {
+		// Mark the output file as closed cleanly, so that it is not salvaged by the next logger
+		err = multierr.Append(err, os.Chmod(rotator.Name(), rotator.Mode()|finished))
+	}
+
+	err = multierr.Append(err, rotator.lock.Close())
+	err = multierr.Append(err, rotator.closeRemote())
+
 	return
 }
 
-// Write to the output file then check if rotation is required
+// closeRemote closes the connection to the Remote address if one is open
+func (rotator *Rotator) closeRemote() error {
+	if rotator.remote == nil {
+		return nil
+	}
+
+	return rotator.remote.Close()
+}
+
+// Handle applies triggers to the Rotator until the context is canceled or a trigger returns an error
+func (rotator *Rotator) Handle(ctx context.Context, triggers <-chan Trigger) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case trigger, ok := <-triggers:
+			if !ok {
+				// Keep waiting for cancellation: a closed trigger channel must not stop the pipe
+				triggers = nil
+				continue
+			}
+
+			if err := trigger(rotator); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Pipe reads from a source io.Reader to the Writer's rotated output file. Rotation is also checked on the
+// CheckInterval and RotateAt boundaries so that idle output files are rotated by age
+func (rotator *Rotator) Pipe(ctx context.Context, src io.Reader) (err error) {
+	opts := rotator.Options()
+
+	if opts.CheckInterval > 0 || (opts.Enabled && opts.RotateAt.Enabled()) {
+		// A failed rotation from the ticker cancels the pipe with its error
+		var cancel context.CancelCauseFunc
+
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		rotator.wait.Add(1)
+		go func() {
+			defer rotator.wait.Done()
+			cancel(rotator.Watch(ctx, opts.CheckInterval))
+		}()
+	}
+
+	return rotator.copy(ctx, src)
+}
+
+// copy reads from a source io.Reader to the output file until EOF or the context is canceled. Input is
+// delivered in whole lines that are selected by the Select rules, prefixed with their arrival time and Prefix.
+// A partial line remaining at EOF is terminated with a newline
+func (rotator *Rotator) copy(ctx context.Context, src io.Reader) error {
+	reader := NewCancelReader(ctx, src)
+	lines := NewLineFramer(int(rotator.Options().MaxLineLength), rotator.prefix, rotator.filter)
+	buffer := make([]byte, 32*1024)
+
+	for {
+		n, err := reader.Read(buffer)
+		if chunk := lines.Frame(buffer[:n]); len(chunk) > 0 {
+			if err := rotator.deliver(ctx, chunk); err != nil {
+				return err
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			if chunk := lines.Rest(); len(chunk) > 0 {
+				if err := rotator.deliver(ctx, chunk); err != nil {
+					return err
+				}
+			}
+
+			// Make a final attempt to write buffered input
+			rotator.Flush()
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Watch checks if the output file needs rotation on every interval and RotateAt boundary until the context is
+// canceled. A zero interval only checks on boundaries. Rotation errors are returned under the ErrorExit policy;
+// other policies count and report them, and retry rotation on the next check
+func (rotator *Rotator) Watch(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(rotator.untilCheck(interval))
+	defer timer.Stop()
+
+	// A failed rotation leaves a RotateAt boundary in the past: retries back off instead of checking immediately
+	var backoff time.Duration
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			if _, err := rotator.Rotate(); err != nil {
+				opts := rotator.Options()
+
+				switch opts.OnError {
+				case ErrorDrop, ErrorBuffer, ErrorRetry:
+					rotator.fail(err, nil)
+
+					if opts.Alerts != nil {
+						fmt.Fprintf(opts.Alerts, "unable to rotate %s: %s\n", rotator.Name(), err)
+					}
+
+					backoff = min(max(backoff*2, RetryMinBackoff), RetryMaxBackoff)
+
+				default:
+					return err
+				}
+			} else {
+				backoff = 0
+			}
+
+			// Free space is checked on every interval. Errors are retried on the next interval
+			rotator.Reclaim()
+
+			timer.Reset(max(rotator.untilCheck(interval), backoff))
+		}
+	}
+}
+
+// untilCheck returns the lesser of the interval and the duration until the next RotateAt boundary
+func (rotator *Rotator) untilCheck(interval time.Duration) time.Duration {
+	rotator.rotating.Lock()
+	defer rotator.rotating.Unlock()
+
+	if !rotator.Enabled || !rotator.RotateAt.Enabled() {
+		return interval
+	}
+
+	if until := time.Until(rotator.boundary); interval <= 0 || until < interval {
+		return until
+	}
+
+	return interval
+}
+
+// Write to the output file then check if rotation is required. If the filesystem is full and MinFree is configured,
+// space is reclaimed and the write is retried once
 func (rotator *Rotator) Write(chunk []byte) (n int, err error) {
-	n, err = rotator.WriteRotator.Write(chunk)
+	n, err = rotator.append(chunk)
 	if err != nil {
 		return
 	}
@@ -189,3 +941,29 @@ func (rotator *Rotator) Write(chunk []byte) (n int, err error) {
 	_, err = rotator.Rotate()
 	return
 }
+
+// writeLines writes input framed by copy to the output file. Rotation is only checked after chunks that end with a
+// newline, so that lines are not split across files
+func (rotator *Rotator) writeLines(chunk []byte) (n int, err error) {
+	n, err = rotator.append(chunk)
+	if err != nil || !bytes.HasSuffix(chunk, []byte{'\n'}) {
+		return
+	}
+
+	_, err = rotator.Rotate()
+	return
+}
+
+// append writes to the output file, reclaiming space and retrying once if the filesystem is full and MinFree is
+// configured
+func (rotator *Rotator) append(chunk []byte) (n int, err error) {
+	n, err = rotator.WriteRotator.Write(chunk)
+	if isNoSpace(err) && rotator.Options().MinFree > 0 && rotator.reclaim(true) == nil {
+		var retried int
+
+		retried, err = rotator.WriteRotator.Write(chunk[n:])
+		n += retried
+	}
+
+	return
+}
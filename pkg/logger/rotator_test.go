@@ -1,13 +1,20 @@
 package logger_test
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/itchyny/timefmt-go"
 	"github.com/jmanero/glug/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"storj.io/common/memory"
 )
 
 func TestRotator(t *testing.T) {
@@ -103,3 +110,809 @@ func TestRotator(t *testing.T) {
 	assert.NoError(t, err, "Globs rotated versions without error")
 	assert.Empty(t, versions, "Zero version exists for count == 0")
 }
+
+func TestRotatorCompress(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    12,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Compress:   "zstd",
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 3; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+	}
+
+	// Close waits for background compression and cleanup
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Compressed versions are counted by cleanup")
+
+	for _, version := range versions {
+		assert.True(t, logger.IsCompressed(version), "Version %s is compressed", version)
+	}
+}
+
+func TestRotatorCompressError(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+	alerts := new(strings.Builder)
+
+	// A directory at the compressed path of a version makes its compression fail
+	version := name + ".2024-01-31T000000"
+	assert.NoError(t, os.WriteFile(version, []byte("Hello world\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(version+".gz", "taken"), 0o755))
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%dT%H%M%S",
+		CreateMode: 0o644,
+		Compress:   "gzip",
+		Alerts:     alerts,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	archived := make(chan error, 1)
+	rotator.OnRotate(func(_ context.Context, event logger.RotateEvent) {
+		if event.Stage == logger.AfterArchive {
+			archived <- event.Err
+		}
+	})
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	_, err = rotator.Force()
+	assert.NoError(t, err, "Force without error")
+
+	assert.Error(t, <-archived, "Reports compression errors in the AfterArchive event")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	assert.Contains(t, alerts.String(), "unable to compress rotated files", "Reports compression errors to Alerts")
+	assert.FileExists(t, version, "Keeps the version that could not be compressed")
+}
+
+func TestRotatorProcessor(t *testing.T) {
+	dir := t.TempDir()
+	alerts := new(strings.Builder)
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    12,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Processor:  "read line; [ $line != fail ] && echo $line | tr a-z A-Z",
+		Alerts:     alerts,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	archived := make(chan struct{}, 2)
+	rotator.OnRotate(func(_ context.Context, event logger.RotateEvent) {
+		if event.Stage == logger.AfterArchive {
+			archived <- struct{}{}
+		}
+	})
+
+	for _, line := range []string{"hello\n", "fail\n"} {
+		_, err = rotator.Write([]byte(line))
+		assert.NoError(t, err, "Write without error")
+
+		_, err = rotator.Force()
+		assert.NoError(t, err, "Rotate without error")
+	}
+
+	// Wait for background processing: Close stops running processors
+	<-archived
+	<-archived
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Keeps processed and failed versions")
+
+	var contents []string
+
+	for _, version := range versions {
+		data, err := os.ReadFile(version)
+		assert.NoError(t, err)
+
+		contents = append(contents, string(data))
+	}
+
+	assert.ElementsMatch(t, []string{"HELLO\n", "fail\n"}, contents, "Replaces processed versions and keeps failed versions")
+	assert.Contains(t, alerts.String(), "failed", "Reports processor failures")
+}
+
+func TestRotatorProcessorClose(t *testing.T) {
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+		Enabled:          true,
+		MaxSize:          1024,
+		MaxAge:           time.Hour,
+		Count:            -1,
+		Pattern:          "%Y-%m-%dT%H%M%S.%f",
+		CreateMode:       0o644,
+		Processor:        "sleep 30; cat",
+		ProcessorTimeout: time.Minute,
+		Alerts:           io.Discard,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	_, err = rotator.Force()
+	assert.NoError(t, err, "Rotate without error")
+
+	start := time.Now()
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+	assert.Less(t, time.Since(start), 5*time.Second, "Close stops a running processor")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Keeps the unprocessed version")
+}
+
+func TestRotatorInvalidCompress(t *testing.T) {
+	_, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{Compress: "lzma"})
+	assert.Error(t, err, "Rejects unsupported compression")
+
+	_, err = logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{Compress: "gzip", CompressLevel: 42})
+	assert.Error(t, err, "Rejects unsupported compression levels")
+
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{Compress: "gzip", CompressLevel: 9, CreateMode: 0o644})
+	assert.NoError(t, err, "Rotator created without error")
+
+	err = rotator.Reconfigure(logger.RotatorOptions{Compress: "gzip", CompressLevel: 42, CreateMode: 0o644})
+	assert.Error(t, err, "Rejects unsupported compression levels on reload")
+	assert.Equal(t, 9, rotator.Options().CompressLevel, "Keeps the current options")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorCompressDelay(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:       true,
+		MaxSize:       12,
+		MaxAge:        time.Hour,
+		Count:         3,
+		Pattern:       "%Y-%m-%dT%H%M%S.%f",
+		CreateMode:    0o644,
+		Compress:      "gzip",
+		CompressDelay: 1,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 3; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+	}
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 3, "Three versions exist after third write")
+
+	assert.True(t, logger.IsCompressed(versions[0]), "Oldest version is compressed")
+	assert.True(t, logger.IsCompressed(versions[1]), "Second version is compressed")
+	assert.False(t, logger.IsCompressed(versions[2]), "Newest version is not compressed")
+}
+
+func TestRotatorForce(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	rotated, err := rotator.Force()
+	assert.NoError(t, err, "Force without error")
+	assert.False(t, rotated, "Force returns false for an empty output file")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Empty(t, versions, "Does not rotate an empty output file")
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	rotated, err = rotator.Force()
+	assert.NoError(t, err, "Force without error")
+	assert.True(t, rotated, "Force returns true after rotation")
+
+	versions, err = rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Rotates below size and age thresholds")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRunTriggers(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+
+	reader, writer := io.Pipe()
+	triggers := make(chan logger.Trigger)
+	done := make(chan error)
+
+	go func() {
+		done <- logger.Run(context.Background(), reader, name, logger.RotatorOptions{
+			Enabled:    true,
+			MaxSize:    1024,
+			MaxAge:     time.Hour,
+			Count:      2,
+			Pattern:    "%Y-%m-%dT%H%M%S.%f",
+			CreateMode: 0o644,
+		}, triggers)
+	}()
+
+	writer.Write([]byte("Hello world\n"))
+
+	assert.Eventually(t, func() bool {
+		stat, err := os.Stat(name)
+		return err == nil && stat.Size() == 12
+	}, time.Second, 10*time.Millisecond, "Writes to output file")
+
+	triggers <- logger.TriggerRotate
+
+	// Handle applies triggers in order: a second send returns after the rotation completes
+	triggers <- func(*logger.Rotator) error { return nil }
+
+	versions, err := filepath.Glob(name + ".[0-9]*")
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Rotates on trigger")
+
+	// Closing the trigger channel does not stop the pipe
+	close(triggers)
+
+	writer.Write([]byte("Hello world\n"))
+	writer.Close()
+
+	assert.NoError(t, <-done, "Run returns without error at EOF")
+
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err, "Test reads back output file")
+	assert.Equal(t, []byte("Hello world\n"), data)
+}
+
+func TestRunTriggerReload(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+	alerts := new(strings.Builder)
+
+	opts := logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Alerts:     alerts,
+	}
+
+	reader, writer := io.Pipe()
+	triggers := make(chan logger.Trigger)
+	done := make(chan error)
+
+	go func() { done <- logger.Run(context.Background(), reader, name, opts, triggers) }()
+
+	// An invalid configuration is reported and does not stop the pipe
+	triggers <- logger.TriggerReload(func() (logger.RotatorOptions, error) { return logger.RotatorOptions{}, io.ErrUnexpectedEOF })
+
+	reloaded := opts
+	reloaded.MaxSize = 12
+	triggers <- logger.TriggerReload(func() (logger.RotatorOptions, error) { return reloaded, nil })
+
+	// Handle applies triggers in order: a second send returns after the reload completes
+	triggers <- func(*logger.Rotator) error { return nil }
+
+	writer.Write([]byte("Hello world\n"))
+	writer.Close()
+
+	assert.NoError(t, <-done, "Run returns without error at EOF")
+	assert.Equal(t, "unable to reload configuration: unexpected EOF\n", alerts.String(), "Reports reload errors")
+
+	versions, err := filepath.Glob(name + ".[0-9]*")
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Rotates with reloaded options")
+}
+
+func TestRotatorReconfigure(t *testing.T) {
+	rotator, err := logger.Open(filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Control:    true,
+		Lock:       logger.LockWait,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	assert.Error(t, rotator.Reconfigure(logger.RotatorOptions{Compress: "lz4"}), "Rejects invalid options")
+
+	err = rotator.Reconfigure(logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    12,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Lock:       logger.LockFail,
+	})
+
+	assert.NoError(t, err, "Reconfigured without error")
+
+	opts := rotator.Options()
+	assert.Equal(t, memory.Size(12), opts.MaxSize, "Replaces options")
+	assert.True(t, opts.Control, "Keeps the Control option")
+	assert.Equal(t, logger.LockWait, opts.Lock, "Keeps the Lock option")
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+	assert.Zero(t, rotator.Size(), "Rotates with new options")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorReconfigureArchiving(t *testing.T) {
+	dir := t.TempDir()
+	opts := logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+		Processor:  "touch started; sleep 1; cat",
+	}
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), opts)
+	assert.NoError(t, err, "Rotator created without error")
+
+	archived := make(chan struct{}, 1)
+	rotator.OnRotate(func(_ context.Context, event logger.RotateEvent) {
+		if event.Stage == logger.AfterArchive {
+			archived <- struct{}{}
+		}
+	})
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	_, err = rotator.Force()
+	assert.NoError(t, err, "Force without error")
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "started"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond, "Processor is started")
+
+	start := time.Now()
+
+	opts.MaxSize = 12
+	assert.NoError(t, rotator.Reconfigure(opts), "Reconfigured without error")
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "Reconfigure does not wait for the Processor")
+
+	<-archived
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRunTriggerError(t *testing.T) {
+	reader, _ := io.Pipe()
+	triggers := make(chan logger.Trigger, 1)
+
+	triggers <- func(*logger.Rotator) error { return io.ErrUnexpectedEOF }
+
+	err := logger.Run(context.Background(), reader, filepath.Join(t.TempDir(), "log"), logger.RotatorOptions{CreateMode: 0o644}, triggers)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "Returns error from a failed trigger")
+}
+
+func TestPipeIdleRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:       true,
+		MaxSize:       1024,
+		MaxAge:        100 * time.Millisecond,
+		Count:         2,
+		CheckInterval: 20 * time.Millisecond,
+		Pattern:       "%Y-%m-%dT%H%M%S.%f",
+		CreateMode:    0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, writer := io.Pipe()
+	done := make(chan error)
+
+	go func() { done <- rotator.Pipe(ctx, reader) }()
+
+	writer.Write([]byte("Hello world\n"))
+
+	assert.Eventually(t, func() bool {
+		versions, err := rotator.Versions()
+		return err == nil && len(versions) == 1
+	}, time.Second, 10*time.Millisecond, "Rotates idle output file by age")
+
+	cancel()
+	assert.NoError(t, <-done, "Pipe returns without error after cancellation")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorSchedule(t *testing.T) {
+	dir := t.TempDir()
+
+	schedule, err := logger.ParseSchedule("@every 1s")
+	assert.NoError(t, err, "Parses schedule")
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MinSize:    1024,
+		MaxAge:     0,
+		Count:      2,
+		RotateAt:   schedule,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	created := rotator.Created()
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Empty(t, versions, "Schedule replaces rotation by MaxAge")
+
+	time.Sleep(1100 * time.Millisecond)
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	versions, err = rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, []string{rotator.Name() + "." + created.UTC().Format("2006-01-02T150405.000000")}, versions, "Names version by the start of its period")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorTimezone(t *testing.T) {
+	dir := t.TempDir()
+
+	var timezone logger.Location
+	assert.Equal(t, "UTC", timezone.String(), "Zero value is UTC")
+	assert.NoError(t, timezone.Set("Etc/GMT-9"), "Loads IANA time zone")
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f%z",
+		Timezone:   timezone,
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	_, err = rotator.Write([]byte("Hello world\n"))
+	assert.NoError(t, err, "Write without error")
+
+	_, err = rotator.Force()
+	assert.NoError(t, err, "Force without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "One version exists after rotation")
+	assert.True(t, strings.HasSuffix(versions[0], "+0900"), "Formats suffix in the configured time zone")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorMaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:      true,
+		MaxSize:      12,
+		MaxAge:       time.Hour,
+		Count:        -1,
+		MaxTotalSize: 30,
+		Pattern:      "%Y-%m-%dT%H%M%S.%f",
+		CreateMode:   0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 4; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+	}
+
+	// Close waits for background cleanup
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Retains versions within the total size budget")
+}
+
+func TestRotatorRetainFor(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "log")
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		RetainFor:  logger.Duration(30 * logger.Day),
+		Pattern:    "%Y-%m-%dT%H%M%S",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	recent := timefmt.Format(time.Now().UTC().Add(-logger.Day), "%Y-%m-%dT%H%M%S")
+	expired := timefmt.Format(time.Now().UTC().Add(-31*logger.Day), "%Y-%m-%dT%H%M%S")
+
+	for _, suffix := range []string{recent, expired + ".gz", "unparsed-recent", "unparsed-expired"} {
+		assert.NoError(t, os.WriteFile(name+"."+suffix, []byte("Hello world\n"), 0o644), "Test writes version")
+	}
+
+	// Files without a timestamp suffix are not versions, regardless of their mtime
+	mtime := time.Now().Add(-31 * logger.Day)
+	assert.NoError(t, os.Chtimes(name+".unparsed-expired", mtime, mtime))
+
+	assert.NoError(t, rotator.Cleanup(), "Cleanup without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Equal(t, []string{name + "." + recent}, versions, "Removes versions older than RetainFor")
+
+	assert.FileExists(t, name+".unparsed-recent", "Does not remove unrelated files")
+	assert.FileExists(t, name+".unparsed-expired", "Does not remove unrelated files")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorReclaim(t *testing.T) {
+	dir := t.TempDir()
+
+	free, err := logger.FreeSpace(dir)
+	assert.NoError(t, err, "Gets free space for test directory")
+	assert.Positive(t, free, "Test directory has free space")
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    12,
+		MaxAge:     time.Hour,
+		Count:      4,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 3; i++ {
+		_, err = rotator.Write([]byte("Hello world\n"))
+		assert.NoError(t, err, "Write without error")
+	}
+
+	_, err = rotator.Write([]byte("Hello"))
+	assert.NoError(t, err, "Write without error")
+
+	assert.NoError(t, rotator.Reclaim(), "Reclaim is disabled without MinFree")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 3, "Retains versions without MinFree")
+
+	// Set a threshold that can not be met to exercise every step of reclamation
+	rotator.MinFree = memory.Size(math.MaxInt64)
+	rotator.MinCount = 1
+	assert.NoError(t, rotator.Reclaim(), "Reclaim without error")
+
+	versions, err = rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Retains MinCount versions below MinFree")
+
+	rotator.MinCount = 0
+	assert.NoError(t, rotator.Reclaim(), "Reclaim without error")
+
+	versions, err = rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Empty(t, versions, "Removes all versions below MinFree")
+
+	assert.Zero(t, rotator.Size(), "Truncates output file as a last resort")
+
+	stat, err := os.Stat(rotator.Name())
+	assert.NoError(t, err, "Stats output file without error")
+	assert.Zero(t, stat.Size(), "Output file is empty after truncation")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestPipeLineBoundaries(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    8,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	reader, writer := io.Pipe()
+	done := make(chan error)
+
+	go func() { done <- rotator.Pipe(context.Background(), reader) }()
+
+	// Rotation is deferred until the line that crosses MaxSize is complete
+	for _, chunk := range []string{"Hello ", "world", "\nHello", " again\npartial"} {
+		writer.Write([]byte(chunk))
+	}
+
+	writer.Close()
+	assert.NoError(t, <-done, "Pipe returns without error at EOF")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := filepath.Glob(filepath.Join(dir, "log.[0-9]*"))
+	assert.NoError(t, err)
+
+	var lines []string
+
+	for _, name := range append(versions, filepath.Join(dir, "log")) {
+		content, err := os.ReadFile(name)
+		assert.NoError(t, err)
+
+		if len(content) > 0 {
+			assert.True(t, strings.HasSuffix(string(content), "\n"), "Files only contain whole lines")
+			lines = append(lines, strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")...)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"Hello world", "Hello again", "partial"}, lines, "All lines are written")
+}
+
+func TestWritePartialLine(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    4,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%dT%H%M%S.%f",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	// Direct writes rotate at MaxSize whether or not they end a line
+	_, err = rotator.Write([]byte("Hello"))
+	assert.NoError(t, err, "Write without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 1, "Rotates after a partial line")
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+}
+
+func TestRotatorBurst(t *testing.T) {
+	dir := t.TempDir()
+
+	// Every rotation within the same day maps to the same timestamp suffix
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      -1,
+		Pattern:    "%Y-%m-%d",
+		CreateMode: 0o644,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 12; i++ {
+		_, err = fmt.Fprintf(rotator, "line %d\n", i)
+		assert.NoError(t, err, "Write without error")
+
+		rotated, err := rotator.Force()
+		assert.NoError(t, err, "Force without error")
+		assert.True(t, rotated, "Force returns true after rotation")
+	}
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 12, "No rotated version is overwritten")
+
+	for i, version := range versions {
+		data, err := os.ReadFile(version)
+		assert.NoError(t, err, "Reads rotated version")
+		assert.Equal(t, fmt.Sprintf("line %d\n", i), string(data), "Versions are ordered by rotation")
+
+		if i > 0 {
+			assert.Equal(t, fmt.Sprintf("%s.%d", versions[0], i), version, "Colliding versions take a counter suffix")
+		}
+	}
+}
+
+func TestRotatorBurstCleanup(t *testing.T) {
+	dir := t.TempDir()
+
+	rotator, err := logger.Open(filepath.Join(dir, "log"), logger.RotatorOptions{
+		Enabled:    true,
+		MaxSize:    1024,
+		MaxAge:     time.Hour,
+		Count:      2,
+		Pattern:    "%Y-%m-%d",
+		CreateMode: 0o644,
+		Compress:   "gzip",
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	for i := 0; i < 5; i++ {
+		_, err = fmt.Fprintf(rotator, "line %d\n", i)
+		assert.NoError(t, err, "Write without error")
+
+		_, err = rotator.Force()
+		assert.NoError(t, err, "Force without error")
+	}
+
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	versions, err := rotator.Versions()
+	assert.NoError(t, err, "Globs rotated versions without error")
+	assert.Len(t, versions, 2, "Cleanup removes the oldest versions")
+
+	// The newest versions are retained after the oldest colliding name is removed
+	for i, version := range versions {
+		assert.True(t, logger.IsCompressed(version), "Version %s is compressed", version)
+		assert.True(t, strings.HasSuffix(logger.TrimCompression(version), fmt.Sprintf(".%d", i+3)), "Version %s follows the highest counter", version)
+	}
+}
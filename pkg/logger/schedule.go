@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule extends a cron.Schedule with the pflag.Value interface. Values are either a cron expression or one of
+// the keywords hourly, daily[@HH:MM], weekly[@DAY] or monthly
+type Schedule struct {
+	cron.Schedule
+
+	spec string
+}
+
+// ParseSchedule parses a schedule keyword or cron expression
+func ParseSchedule(value string) (schedule Schedule, err error) {
+	err = schedule.Set(value)
+	return
+}
+
+// Set value from a string argument
+func (schedule *Schedule) Set(value string) (err error) {
+	if value == "" {
+		*schedule = Schedule{}
+		return
+	}
+
+	expression, err := expandSchedule(value)
+	if err != nil {
+		return
+	}
+
+	parsed, err := cron.ParseStandard(expression)
+	if err != nil {
+		return
+	}
+
+	*schedule = Schedule{Schedule: parsed, spec: value}
+	return
+}
+
+func (schedule Schedule) String() string {
+	return schedule.spec
+}
+
+// Type description for CLI usage
+func (Schedule) Type() string {
+	return "schedule"
+}
+
+// Enabled checks if the schedule has been set
+func (schedule Schedule) Enabled() bool {
+	return schedule.Schedule != nil
+}
+
+// expandSchedule converts schedule keywords to cron expressions
+func expandSchedule(value string) (string, error) {
+	keyword, at, _ := strings.Cut(value, "@")
+
+	switch keyword {
+	case "hourly":
+		if at == "" {
+			return "0 * * * *", nil
+		}
+
+	case "daily":
+		if at == "" {
+			return "0 0 * * *", nil
+		}
+
+		clock, err := time.Parse("15:04", at)
+		if err != nil {
+			return "", fmt.Errorf("invalid time of day in schedule %q", value)
+		}
+
+		return fmt.Sprintf("%d %d * * *", clock.Minute(), clock.Hour()), nil
+
+	case "weekly":
+		if at == "" {
+			return "0 0 * * 0", nil
+		}
+
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(at, day.String()) || strings.EqualFold(at, day.String()[:3]) {
+				return fmt.Sprintf("0 0 * * %d", day), nil
+			}
+		}
+
+		return "", fmt.Errorf("invalid day of week in schedule %q", value)
+
+	case "monthly":
+		if at == "" {
+			return "0 0 1 * *", nil
+		}
+
+	default:
+		// Cron expression or descriptor
+		return value, nil
+	}
+
+	return "", fmt.Errorf("schedule %q does not accept a time", keyword)
+}
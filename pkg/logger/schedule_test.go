@@ -0,0 +1,56 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	// Thursday
+	now := time.Date(2023, time.November, 23, 13, 45, 10, 0, time.UTC)
+
+	for spec, next := range map[string]time.Time{
+		"hourly":        time.Date(2023, time.November, 23, 14, 0, 0, 0, time.UTC),
+		"daily":         time.Date(2023, time.November, 24, 0, 0, 0, 0, time.UTC),
+		"daily@06:30":   time.Date(2023, time.November, 24, 6, 30, 0, 0, time.UTC),
+		"daily@18:00":   time.Date(2023, time.November, 23, 18, 0, 0, 0, time.UTC),
+		"weekly":        time.Date(2023, time.November, 26, 0, 0, 0, 0, time.UTC),
+		"weekly@Mon":    time.Date(2023, time.November, 27, 0, 0, 0, 0, time.UTC),
+		"weekly@friday": time.Date(2023, time.November, 24, 0, 0, 0, 0, time.UTC),
+		"monthly":       time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC),
+		"*/15 * * * *":  time.Date(2023, time.November, 23, 14, 0, 0, 0, time.UTC),
+		"@daily":        time.Date(2023, time.November, 24, 0, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := logger.ParseSchedule(spec)
+		assert.NoError(t, err, "Parses schedule %q", spec)
+		assert.True(t, schedule.Enabled())
+		assert.Equal(t, spec, schedule.String())
+		assert.Equal(t, next, schedule.Next(now), "Next boundary for schedule %q", spec)
+	}
+
+	for _, spec := range []string{"daily@25:00", "weekly@Someday", "hourly@12:00", "0 12 * * MON-FR"} {
+		_, err := logger.ParseSchedule(spec)
+		assert.Error(t, err, "Rejects invalid schedule %q", spec)
+	}
+
+	schedule, err := logger.ParseSchedule("")
+	assert.NoError(t, err, "Parses empty schedule")
+	assert.False(t, schedule.Enabled(), "Empty schedule is disabled")
+}
+
+func TestScheduleTimezone(t *testing.T) {
+	var timezone logger.Location
+	assert.NoError(t, timezone.Set("America/New_York"), "Loads IANA time zone")
+
+	schedule, err := logger.ParseSchedule("daily")
+	assert.NoError(t, err, "Parses schedule")
+
+	now := time.Date(2023, time.November, 23, 13, 45, 10, 0, time.UTC)
+	next := schedule.Next(now.In(timezone.Get()))
+
+	assert.Equal(t, time.Date(2023, time.November, 24, 0, 0, 0, 0, timezone.Get()), next, "Boundaries follow the time zone of the current time")
+	assert.Equal(t, time.Date(2023, time.November, 24, 5, 0, 0, 0, time.UTC), next.UTC())
+}
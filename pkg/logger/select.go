@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Selector is an svlogd line selection rule. Its action is one of:
+//   - `+` selects matching lines for the output file
+//   - `-` deselects matching lines from the output file
+//   - `e` selects matching lines for alerts on stderr
+//   - `E` deselects matching lines from alerts
+type Selector struct {
+	Action  byte
+	Pattern string
+}
+
+// ParseSelector parses a selection rule from an action character followed by a pattern, e.g. `-*health*`
+func ParseSelector(rule string) (Selector, error) {
+	if len(rule) > 0 && strings.IndexByte("+-eE", rule[0]) >= 0 {
+		return Selector{Action: rule[0], Pattern: rule[1:]}, nil
+	}
+
+	return Selector{}, fmt.Errorf("invalid selection rule %q: must begin with one of +, -, e or E", rule)
+}
+
+func (selector Selector) String() string {
+	return string(selector.Action) + selector.Pattern
+}
+
+// Selectors are applied in order to each line of input. The last matching rule for the output file and for
+// alerts decides whether a line is written to each. All lines are selected for the output file and none for
+// alerts by default
+type Selectors []Selector
+
+// Set appends a rule from a string argument
+func (selectors *Selectors) Set(value string) error {
+	selector, err := ParseSelector(value)
+	if err != nil {
+		return err
+	}
+
+	*selectors = append(*selectors, selector)
+	return nil
+}
+
+func (selectors Selectors) String() string {
+	rules := make([]string, len(selectors))
+	for i, selector := range selectors {
+		rules[i] = selector.String()
+	}
+
+	return "[" + strings.Join(rules, ",") + "]"
+}
+
+// Type description for CLI usage
+func (Selectors) Type() string {
+	return "rule"
+}
+
+// ReadSelectors parses one rule per line from a file. Blank lines and lines beginning with `#` are ignored
+func ReadSelectors(name string) (selectors Selectors, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" && line[0] != '#' {
+			if err = selectors.Set(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return selectors, scanner.Err()
+}
+
+// Select checks if a line, excluding its newline, is selected for the output file and for alerts
+func (selectors Selectors) Select(line []byte) (log, alert bool) {
+	log = true
+
+	for _, selector := range selectors {
+		if !PatternMatch(selector.Pattern, line) {
+			continue
+		}
+
+		switch selector.Action {
+		case '+', '-':
+			log = selector.Action == '+'
+		case 'e', 'E':
+			alert = selector.Action == 'e'
+		}
+	}
+
+	return
+}
+
+// filter applies the Select rules to a line of input, writing lines selected for alerts to Alerts and sending
+// selected lines to the Remote address. It returns true if the line is selected for the output file
+func (rotator *Rotator) filter(line []byte) bool {
+	rotator.settings.RLock()
+	defer rotator.settings.RUnlock()
+
+	log, alert := rotator.Select.Select(line)
+	if alert && rotator.Alerts != nil {
+		fmt.Fprintf(rotator.Alerts, "%s%s\n", rotator.Prefix, line)
+	}
+
+	if log && rotator.remote != nil {
+		rotator.send(line)
+		return !rotator.RemoteOnly
+	}
+
+	return log
+}
+
+// PatternMatch implements svlogd's pattern matching. A pattern must match the whole line: `*` matches any
+// sequence of characters up to the next character of the pattern, `+` matches one or more repetitions of the
+// next character of the pattern, and all other characters match themselves
+func PatternMatch(pattern string, line []byte) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 == len(pattern) {
+				return true
+			}
+
+			for len(line) > 0 && line[0] != pattern[i+1] {
+				line = line[1:]
+			}
+
+			if len(line) == 0 {
+				return false
+			}
+
+		case '+':
+			i++
+			if i == len(pattern) || len(line) == 0 || line[0] != pattern[i] {
+				return false
+			}
+
+			for len(line) > 0 && line[0] == pattern[i] {
+				line = line[1:]
+			}
+
+			// Following svlogd, a repetition that consumes the rest of the line matches
+			if len(line) == 0 {
+				return true
+			}
+
+		default:
+			if len(line) == 0 || line[0] != c {
+				return false
+			}
+
+			line = line[1:]
+		}
+	}
+
+	return len(line) == 0
+}
@@ -0,0 +1,92 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternMatch(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		line    string
+		match   bool
+	}{
+		{"hello", "hello", true},
+		{"hello", "hello world", false},
+		{"*", "anything", true},
+		{"*", "", true},
+		{"GET /health*", "GET /health 200", true},
+		{"*health*", "GET /health 200", true},
+		{"*health*", "GET /status 200", false},
+		{"*: 200", "GET /health: 200", true},
+		{"a+bc", "abbbc", true},
+		{"a+bc", "ac", false},
+		{"a+b", "abbb", true},
+		{"", "", true},
+		{"", "a", false},
+	} {
+		assert.Equal(t, test.match, logger.PatternMatch(test.pattern, []byte(test.line)), "Pattern %q matching %q", test.pattern, test.line)
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	var selectors logger.Selectors
+
+	assert.NoError(t, selectors.Set("-*health*"))
+	assert.NoError(t, selectors.Set("+*health* failed"))
+	assert.NoError(t, selectors.Set("e*ERROR*"))
+	assert.Error(t, selectors.Set("*debug*"), "Rejects rules without an action")
+	assert.Equal(t, "[-*health*,+*health* failed,e*ERROR*]", selectors.String())
+
+	log, alert := selectors.Select([]byte("GET /index"))
+	assert.True(t, log, "Selects lines by default")
+	assert.False(t, alert, "Does not alert by default")
+
+	log, _ = selectors.Select([]byte("GET /health"))
+	assert.False(t, log, "Deselects matching lines")
+
+	log, _ = selectors.Select([]byte("GET /health failed"))
+	assert.True(t, log, "The last matching rule applies")
+
+	log, alert = selectors.Select([]byte("ERROR: disk"))
+	assert.True(t, log, "Alerts are also logged")
+	assert.True(t, alert, "Alerts matching lines")
+}
+
+func TestReadSelectors(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "select")
+	os.WriteFile(name, []byte("# Drop health-checks\n-*health*\n\neERROR*\n"), 0o644)
+
+	selectors, err := logger.ReadSelectors(name)
+	assert.NoError(t, err, "Reads rules without error")
+	assert.Equal(t, logger.Selectors{{Action: '-', Pattern: "*health*"}, {Action: 'e', Pattern: "ERROR*"}}, selectors)
+}
+
+func TestPipeSelect(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "log")
+	alerts := new(bytes.Buffer)
+
+	rotator, err := logger.Open(name, logger.RotatorOptions{
+		CreateMode: 0o644,
+		Select:     logger.Selectors{{Action: '-', Pattern: "*health*"}, {Action: 'e', Pattern: "ERROR*"}},
+		Alerts:     alerts,
+	})
+
+	assert.NoError(t, err, "Rotator created without error")
+
+	err = rotator.Pipe(context.Background(), strings.NewReader("GET /index\nGET /health\nERROR: disk\n"))
+	assert.NoError(t, err, "Pipe returns without error at EOF")
+	assert.NoError(t, rotator.Close(), "Rotator closed without error")
+
+	content, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET /index\nERROR: disk\n", string(content), "Writes selected lines")
+	assert.Equal(t, "ERROR: disk\n", alerts.String(), "Writes alerts")
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"syscall"
+
+	"go.uber.org/multierr"
+)
+
+// FreeSpace returns the number of bytes available to unprivileged users on the filesystem containing path
+func FreeSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// Reclaim removes the oldest rotated versions while free space on the output file's filesystem is below MinFree,
+// retaining at least MinCount versions. Versions in an ArchiveDir on another filesystem are not removed. If free
+// space is still below MinFree, the output file is truncated
+func (rotator *Rotator) Reclaim() error {
+	return rotator.reclaim(false)
+}
+
+// reclaim removes at least one version or truncates the output file if full is set, e.g. after a write returned ENOSPC
+func (rotator *Rotator) reclaim(full bool) (err error) {
+	rotator.maintenance.Lock()
+	defer rotator.maintenance.Unlock()
+
+	opts := rotator.Options()
+	if opts.MinFree <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(rotator.Name())
+
+	low := func() bool {
+		if full {
+			return true
+		}
+
+		free, serr := FreeSpace(dir)
+		if serr != nil {
+			err = multierr.Append(err, serr)
+			return false
+		}
+
+		return free < int64(opts.MinFree)
+	}
+
+	if !low() {
+		return
+	}
+
+	var versions []string
+
+	// Removing versions from an ArchiveDir on another filesystem does not free space for the output file
+	if opts.ArchiveDir == "" || sameFilesystem(dir, opts.ArchiveDir) {
+		var verr error
+
+		versions, verr = opts.listVersions(rotator.Name())
+		err = multierr.Append(err, verr)
+
+		// Retain the newest MinCount versions
+		versions = versions[:max(len(versions)-opts.MinCount, 0)]
+	}
+
+	// Remove oldest (first in sorted slice) rotated files first
+	for _, version := range versions {
+		err = multierr.Append(err, removeVersion(version))
+		full = false
+
+		if !low() {
+			return
+		}
+	}
+
+	// Last resort: discard the contents of the output file
+	rotator.rotating.Lock()
+	defer rotator.rotating.Unlock()
+
+	return multierr.Append(err, rotator.Truncate())
+}
+
+// isNoSpace checks for a write error caused by a full filesystem
+func isNoSpace(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
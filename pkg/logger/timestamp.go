@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/itchyny/timefmt-go"
+)
+
+// Timestamp selects the format of the arrival time prefixed to each line of input
+type Timestamp string
+
+// Supported Timestamp formats. Any other value containing a `%` is a strftime format string
+const (
+	TimestampNone        Timestamp = ""
+	TimestampTAI64N      Timestamp = "tai64n"
+	TimestampRFC3339     Timestamp = "rfc3339"
+	TimestampRFC3339Nano Timestamp = "rfc3339nano"
+)
+
+// Set value from a string argument
+func (stamp *Timestamp) Set(value string) error {
+	switch Timestamp(value) {
+	case TimestampNone, TimestampTAI64N, TimestampRFC3339, TimestampRFC3339Nano:
+	default:
+		if !strings.Contains(value, "%") {
+			return fmt.Errorf("unsupported timestamp format %q", value)
+		}
+	}
+
+	*stamp = Timestamp(value)
+	return nil
+}
+
+func (stamp Timestamp) String() string {
+	return string(stamp)
+}
+
+// Type description for CLI usage
+func (Timestamp) Type() string {
+	return "tai64n|rfc3339|rfc3339nano|strftime"
+}
+
+// Append a formatted time and a separating space to dst
+func (stamp Timestamp) Append(dst []byte, t time.Time) []byte {
+	switch stamp {
+	case TimestampNone:
+		return dst
+	case TimestampTAI64N:
+		dst = AppendTAI64N(dst, t)
+	case TimestampRFC3339:
+		dst = t.AppendFormat(dst, time.RFC3339)
+	case TimestampRFC3339Nano:
+		dst = t.AppendFormat(dst, time.RFC3339Nano)
+	default:
+		dst = timefmt.AppendFormat(dst, t, string(stamp))
+	}
+
+	return append(dst, ' ')
+}
+
+// prefix appends the configured Timestamp of the current time and the Prefix option to dst
+func (rotator *Rotator) prefix(dst []byte) []byte {
+	rotator.settings.RLock()
+	defer rotator.settings.RUnlock()
+
+	if rotator.Timestamp == TimestampNone {
+		return append(dst, rotator.Prefix...)
+	}
+
+	return append(rotator.Timestamp.Append(dst, rotator.now()), rotator.Prefix...)
+}
+
+// tai64Epoch is the TAI64 label of the unix epoch, including the 10 second offset of TAI from UTC used by daemontools
+const tai64Epoch = 1<<62 + 10
+
+// ParseTAI64N parses an external TAI64N label, e.g. `@4000000068f1a2b30a1b2c3d`
+func ParseTAI64N(label string) (time.Time, error) {
+	var seconds uint64
+	var nanoseconds uint32
+
+	if len(label) != 25 || label[0] != '@' {
+		return time.Time{}, fmt.Errorf("invalid TAI64N label %q", label)
+	}
+
+	_, err := fmt.Sscanf(label[1:], "%016x%08x", &seconds, &nanoseconds)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid TAI64N label %q: %w", label, err)
+	}
+
+	return time.Unix(int64(seconds-tai64Epoch), int64(nanoseconds)), nil
+}
+
+// AppendTAI64N appends the external TAI64N label of a time, e.g. `@4000000068f1a2b30a1b2c3d`, to dst
+func AppendTAI64N(dst []byte, t time.Time) []byte {
+	return fmt.Appendf(dst, "@%016x%08x", uint64(tai64Epoch+t.Unix()), t.Nanosecond())
+}
@@ -0,0 +1,40 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmanero/glug/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendTAI64N(t *testing.T) {
+	assert.Equal(t, "@400000000000000a00000000", string(logger.AppendTAI64N(nil, time.Unix(0, 0))), "Formats the unix epoch")
+	assert.Equal(t, "@4000000068f1a2b2000003e8", string(logger.AppendTAI64N(nil, time.Unix(1760666280, 1000))), "Formats seconds and nanoseconds")
+}
+
+func TestTimestamp(t *testing.T) {
+	when := time.Date(2025, 10, 17, 1, 2, 3, 456000000, time.UTC)
+
+	for stamp, expected := range map[logger.Timestamp]string{
+		logger.TimestampNone:        "",
+		logger.TimestampTAI64N:      "@4000000068f195951b2e0200 ",
+		logger.TimestampRFC3339:     "2025-10-17T01:02:03Z ",
+		logger.TimestampRFC3339Nano: "2025-10-17T01:02:03.456Z ",
+		"%Y-%m-%d %H:%M:%S":         "2025-10-17 01:02:03 ",
+	} {
+		assert.Equal(t, expected, string(stamp.Append(nil, when)), "Formats %q timestamps", stamp)
+	}
+}
+
+func TestTimestampFlag(t *testing.T) {
+	var stamp logger.Timestamp
+
+	assert.NoError(t, stamp.Set("tai64n"), "Accepts named formats")
+	assert.Equal(t, logger.TimestampTAI64N, stamp)
+
+	assert.NoError(t, stamp.Set("%H:%M:%S"), "Accepts strftime formats")
+	assert.Equal(t, logger.Timestamp("%H:%M:%S"), stamp)
+
+	assert.Error(t, stamp.Set("unix"), "Rejects unknown formats")
+}
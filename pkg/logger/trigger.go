@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"fmt"
+)
+
+// Trigger is an out-of-band action applied to a running Rotator, e.g. from a signal handler
+type Trigger func(*Rotator) error
+
+var (
+	// TriggerRotate forces rotation of a non-empty output file
+	TriggerRotate Trigger = func(rotator *Rotator) (err error) {
+		_, err = rotator.Force()
+		return
+	}
+
+	// TriggerReopen closes and reopens the output file
+	TriggerReopen Trigger = (*Rotator).Refresh
+)
+
+// TriggerReload returns a Trigger that replaces the Rotator's options with the result of load, then reopens the
+// output file. Errors from load are written to Alerts and the current options are kept, so that an invalid
+// configuration does not stop a running logger
+func TriggerReload(load func() (RotatorOptions, error)) Trigger {
+	return func(rotator *Rotator) error {
+		opts, err := load()
+		if err == nil {
+			err = rotator.Reconfigure(opts)
+		}
+
+		if alerts := rotator.Options().Alerts; err != nil && alerts != nil {
+			fmt.Fprintln(alerts, "unable to reload configuration:", err)
+		}
+
+		return rotator.Refresh()
+	}
+}